@@ -0,0 +1,60 @@
+package analytics
+
+import (
+    "fmt"
+
+    "cv-extractor/models"
+    "gorm.io/gorm"
+)
+
+// What a breakdown can group candidates by.
+const (
+    GroupByDepartment = "department"
+    GroupByPosition   = "position"
+)
+
+// GroupStats reports how the candidates of one department or position who
+// applied in the filter's range fared. Rates are percentages of
+// Applications.
+type GroupStats struct {
+    ID                uint    `json:"id"`
+    Name              string  `json:"name"`
+    Applications      int64   `json:"applications"`
+    Interviewed       int64   `json:"interviewed"`
+    Hires             int64   `json:"hires"`
+    InterviewRate     float64 `json:"interviewRate"`
+    HireRate          float64 `json:"hireRate"`
+    AverageDaysToHire float64 `json:"averageDaysToHire"`
+    MedianDaysToHire  float64 `json:"medianDaysToHire"`
+}
+
+// Breakdown compares the departments or positions of the filter, busiest
+// first. Those without applications in the range are left out.
+func Breakdown(db *gorm.DB, f Filter, by string) ([]GroupStats, error) {
+    if by != GroupByDepartment && by != GroupByPosition {
+        return nil, fmt.Errorf("unknown grouping %q", by)
+    }
+
+    var groups []GroupStats
+    if err := f.cohort(db).
+        Select(by+`_id AS id, `+by+`_name AS name, COUNT(*) AS applications,
+            COUNT(*) FILTER (WHERE reached >= ?) AS interviewed,
+            COUNT(*) FILTER (WHERE reached >= ?) AS hires,
+            COALESCE(AVG(days_to_hire), 0) AS average_days_to_hire,
+            COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY days_to_hire), 0) AS median_days_to_hire`,
+            rankOf(models.StageInterview), rankOf(models.StageHired)).
+        Group(by + "_id, " + by + "_name").
+        Order("applications DESC, name").
+        Scan(&groups).Error; err != nil {
+        return nil, err
+    }
+
+    for i := range groups {
+        group := &groups[i]
+        group.InterviewRate = percent(group.Interviewed, group.Applications)
+        group.HireRate = percent(group.Hires, group.Applications)
+        group.AverageDaysToHire = round(group.AverageDaysToHire)
+        group.MedianDaysToHire = round(group.MedianDaysToHire)
+    }
+    return groups, nil
+}
@@ -0,0 +1,81 @@
+package analytics
+
+import (
+    "fmt"
+    "math"
+    "strings"
+
+    "cv-extractor/models"
+    "gorm.io/gorm"
+)
+
+// funnelStages are the steps of the hiring funnel in order. Candidates can be
+// rejected at any step, so rejection is not a step of its own.
+var funnelStages = []string{models.StageApplied, models.StageScreening, models.StageInterview, models.StageOffer, models.StageHired}
+
+// stageRank returns SQL numbering the stage in column by its step in the
+// funnel, from 1, or NULL for a stage outside it.
+func stageRank(column string) string {
+    var b strings.Builder
+    b.WriteString("CASE " + column)
+    for i, stage := range funnelStages {
+        fmt.Fprintf(&b, " WHEN '%s' THEN %d", stage, i+1)
+    }
+    b.WriteString(" END")
+    return b.String()
+}
+
+// rankOf returns the funnel step of stage, from 1.
+func rankOf(stage string) int {
+    for i, s := range funnelStages {
+        if s == stage {
+            return i + 1
+        }
+    }
+    return 0
+}
+
+// reachedRank is SQL for the furthest funnel step a candidate reached,
+// judged by its current stage and its stage history so that rejected
+// candidates count towards the steps they passed.
+var reachedRank = fmt.Sprintf(
+    "COALESCE(GREATEST(%s, (SELECT MAX(%s) FROM candidate_stage_histories WHERE candidate_stage_histories.candidate_id = candidates.id)), 1)",
+    stageRank("candidates.stage"), stageRank("candidate_stage_histories.to_stage"),
+)
+
+// joinHires joins the date each candidate was first moved to hired as
+// hires.hired_date. Candidates hired before stage history was recorded have
+// no hire date and are left out of time-to-hire figures.
+func joinHires(query *gorm.DB, join string) *gorm.DB {
+    return query.Joins(join+" (SELECT candidate_id, MIN(changed_date) AS hired_date FROM candidate_stage_histories WHERE to_stage = ? GROUP BY candidate_id) hires ON hires.candidate_id = candidates.id", models.StageHired)
+}
+
+// daysToHire is SQL for how many days a hired candidate took from applying,
+// or NULL for a candidate without a hire date. A copy of a candidate can
+// carry a hire date older than itself; that counts as no time at all.
+const daysToHire = "EXTRACT(EPOCH FROM hires.hired_date - LEAST(candidates.created_date, hires.hired_date)) / 86400"
+
+// cohort selects one row per candidate who applied in the filter's range,
+// with the columns the funnel, source and breakdown reports group by.
+func (f Filter) cohort(db *gorm.DB) *gorm.DB {
+    query := f.inRange(joinHires(f.candidates(db), "LEFT JOIN"), "candidates.created_date")
+    return db.Table("(?) AS cohort", query.Select(`candidates.stage, candidates.is_qualified, candidates.score, candidates.scored_date,
+        COALESCE(NULLIF(candidates.source, ''), ?) AS source,
+        departments.id AS department_id, departments.name AS department_name,
+        positions.id AS position_id, positions.name AS position_name,
+        `+daysToHire+` AS days_to_hire,
+        `+reachedRank+` AS reached`, models.SourceUnknown))
+}
+
+// percent returns part as a percentage of whole, to one decimal.
+func percent(part, whole int64) float64 {
+    if whole == 0 {
+        return 0
+    }
+    return round(float64(part) / float64(whole) * 100)
+}
+
+// round rounds to one decimal.
+func round(value float64) float64 {
+    return math.Round(value*10) / 10
+}
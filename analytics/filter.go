@@ -0,0 +1,109 @@
+package analytics
+
+import (
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Intervals a time series can be grouped by. Weeks start on Monday.
+const (
+    IntervalDay   = "day"
+    IntervalWeek  = "week"
+    IntervalMonth = "month"
+)
+
+// maxPeriods caps how many periods a time series may span.
+const maxPeriods = 400
+
+// Filter selects the candidates a report covers: those of one company,
+// optionally narrowed to a department or position, with activity between
+// From (inclusive) and To (exclusive). Times are bucketed in UTC.
+type Filter struct {
+    CompanyID    uint
+    DepartmentID uint
+    PositionID   uint
+    From         time.Time
+    To           time.Time
+    Interval     string
+}
+
+// Validate reports a filter whose range or interval cannot be reported on.
+func (f Filter) Validate() error {
+    switch f.Interval {
+    case IntervalDay, IntervalWeek, IntervalMonth:
+    default:
+        return fmt.Errorf("unknown interval %q", f.Interval)
+    }
+    if !f.From.Before(f.To) {
+        return fmt.Errorf("from must be before to")
+    }
+    if len(f.Periods()) > maxPeriods {
+        return fmt.Errorf("range spans more than %d %ss", maxPeriods, f.Interval)
+    }
+    return nil
+}
+
+// Periods lists the start of every period in the filter's range.
+func (f Filter) Periods() []time.Time {
+    var periods []time.Time
+    for period := f.truncate(f.From); period.Before(f.To) && len(periods) <= maxPeriods; period = f.next(period) {
+        periods = append(periods, period)
+    }
+    return periods
+}
+
+// truncate returns the start of the period t falls in, matching Postgres'
+// date_trunc.
+func (f Filter) truncate(t time.Time) time.Time {
+    t = t.UTC()
+    day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+    switch f.Interval {
+    case IntervalDay:
+        return day
+    case IntervalWeek:
+        return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+    default:
+        return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+    }
+}
+
+// next returns the start of the period after the one starting at period.
+func (f Filter) next(period time.Time) time.Time {
+    switch f.Interval {
+    case IntervalDay:
+        return period.AddDate(0, 0, 1)
+    case IntervalWeek:
+        return period.AddDate(0, 0, 7)
+    default:
+        return period.AddDate(0, 1, 0)
+    }
+}
+
+// periodKey identifies a period when matching query rows to Periods.
+func periodKey(period time.Time) string {
+    return period.UTC().Format("2006-01-02")
+}
+
+// candidates selects the live candidates of the filter's company, department
+// and position, joining positions and departments. It does not apply the
+// date range, since each report dates candidates differently.
+func (f Filter) candidates(db *gorm.DB) *gorm.DB {
+    query := db.Table("candidates").
+        Joins("JOIN positions ON candidates.position_id = positions.id").
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("departments.company_id = ? AND candidates.deleted_at IS NULL", f.CompanyID)
+    if f.DepartmentID != 0 {
+        query = query.Where("departments.id = ?", f.DepartmentID)
+    }
+    if f.PositionID != 0 {
+        query = query.Where("positions.id = ?", f.PositionID)
+    }
+    return query
+}
+
+// inRange restricts query to rows whose column falls in the filter's range.
+func (f Filter) inRange(query *gorm.DB, column string) *gorm.DB {
+    return query.Where(column+" >= ? AND "+column+" < ?", f.From, f.To)
+}
@@ -0,0 +1,59 @@
+package analytics
+
+import (
+    "cv-extractor/models"
+    "gorm.io/gorm"
+)
+
+// FunnelStep counts the candidates who reached a step of the funnel, as a
+// percentage of the previous step and of everyone who applied.
+type FunnelStep struct {
+    Stage      string  `json:"stage"`
+    Candidates int64   `json:"candidates"`
+    Conversion float64 `json:"conversion"`
+    Overall    float64 `json:"overall"`
+}
+
+// Funnel follows the candidates who applied in the filter's range through
+// the hiring funnel.
+type Funnel struct {
+    Steps    []FunnelStep `json:"steps"`
+    Rejected int64        `json:"rejected"`
+}
+
+// HiringFunnel counts how far the candidates who applied in the filter's
+// range got, whether or not they were rejected later on.
+func HiringFunnel(db *gorm.DB, f Filter) (Funnel, error) {
+    var rows []struct {
+        Reached    int
+        Candidates int64
+        Rejected   int64
+    }
+    if err := f.cohort(db).
+        Select("reached, COUNT(*) AS candidates, COUNT(*) FILTER (WHERE stage = ?) AS rejected", models.StageRejected).
+        Group("reached").
+        Scan(&rows).Error; err != nil {
+        return Funnel{}, err
+    }
+
+    // A candidate who reached a step also passed every step before it.
+    reached := make([]int64, len(funnelStages)+1)
+    funnel := Funnel{Steps: make([]FunnelStep, len(funnelStages))}
+    for _, row := range rows {
+        for rank := 1; rank <= row.Reached && rank <= len(funnelStages); rank++ {
+            reached[rank] += row.Candidates
+        }
+        funnel.Rejected += row.Rejected
+    }
+
+    for i, stage := range funnelStages {
+        step := FunnelStep{Stage: stage, Candidates: reached[i+1], Overall: percent(reached[i+1], reached[1])}
+        if i == 0 {
+            step.Conversion = step.Overall
+        } else {
+            step.Conversion = percent(reached[i+1], reached[i])
+        }
+        funnel.Steps[i] = step
+    }
+    return funnel, nil
+}
@@ -0,0 +1,68 @@
+package analytics
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Point is one period of a time series. Applications are dated by when the
+// candidate applied and hires by when they were moved to hired.
+type Point struct {
+    Period            time.Time `json:"period"`
+    Applications      int64     `json:"applications"`
+    Hires             int64     `json:"hires"`
+    AverageDaysToHire float64   `json:"averageDaysToHire"`
+    MedianDaysToHire  float64   `json:"medianDaysToHire"`
+}
+
+// TimeSeries counts applications and hires per period, with the time hires
+// took from applying.
+func TimeSeries(db *gorm.DB, f Filter) ([]Point, error) {
+    var applications []struct {
+        Period time.Time
+        Count  int64
+    }
+    if err := f.inRange(f.candidates(db), "candidates.created_date").
+        Select("date_trunc(?, candidates.created_date AT TIME ZONE 'UTC') AS period, COUNT(*) AS count", f.Interval).
+        Group("period").
+        Scan(&applications).Error; err != nil {
+        return nil, err
+    }
+
+    var hires []struct {
+        Period            time.Time
+        Hires             int64
+        AverageDaysToHire float64
+        MedianDaysToHire  float64
+    }
+    if err := f.inRange(joinHires(f.candidates(db), "JOIN"), "hires.hired_date").
+        Select(`date_trunc(?, hires.hired_date AT TIME ZONE 'UTC') AS period, COUNT(*) AS hires,
+            AVG(`+daysToHire+`) AS average_days_to_hire,
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY `+daysToHire+`) AS median_days_to_hire`, f.Interval).
+        Group("period").
+        Scan(&hires).Error; err != nil {
+        return nil, err
+    }
+
+    periods := f.Periods()
+    points := make([]Point, len(periods))
+    index := make(map[string]int, len(periods))
+    for i, period := range periods {
+        points[i].Period = period
+        index[periodKey(period)] = i
+    }
+    for _, row := range applications {
+        if i, ok := index[periodKey(row.Period)]; ok {
+            points[i].Applications = row.Count
+        }
+    }
+    for _, row := range hires {
+        if i, ok := index[periodKey(row.Period)]; ok {
+            points[i].Hires = row.Hires
+            points[i].AverageDaysToHire = round(row.AverageDaysToHire)
+            points[i].MedianDaysToHire = round(row.MedianDaysToHire)
+        }
+    }
+    return points, nil
+}
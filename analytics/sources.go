@@ -0,0 +1,47 @@
+package analytics
+
+import (
+    "cv-extractor/models"
+    "gorm.io/gorm"
+)
+
+// SourceStats reports how the candidates who came through one source and
+// applied in the filter's range fared. Rates are percentages of Candidates.
+type SourceStats struct {
+    Source            string  `json:"source"`
+    Candidates        int64   `json:"candidates"`
+    Qualified         int64   `json:"qualified"`
+    Interviewed       int64   `json:"interviewed"`
+    Hired             int64   `json:"hired"`
+    InterviewRate     float64 `json:"interviewRate"`
+    HireRate          float64 `json:"hireRate"`
+    AverageScore      float64 `json:"averageScore"`
+    AverageDaysToHire float64 `json:"averageDaysToHire"`
+}
+
+// SourceEffectiveness compares candidate sources, busiest first.
+func SourceEffectiveness(db *gorm.DB, f Filter) ([]SourceStats, error) {
+    var sources []SourceStats
+    if err := f.cohort(db).
+        Select(`source, COUNT(*) AS candidates,
+            COUNT(*) FILTER (WHERE is_qualified) AS qualified,
+            COUNT(*) FILTER (WHERE reached >= ?) AS interviewed,
+            COUNT(*) FILTER (WHERE reached >= ?) AS hired,
+            COALESCE(AVG(score) FILTER (WHERE scored_date IS NOT NULL), 0) AS average_score,
+            COALESCE(AVG(days_to_hire), 0) AS average_days_to_hire`,
+            rankOf(models.StageInterview), rankOf(models.StageHired)).
+        Group("source").
+        Order("candidates DESC, source").
+        Scan(&sources).Error; err != nil {
+        return nil, err
+    }
+
+    for i := range sources {
+        source := &sources[i]
+        source.InterviewRate = percent(source.Interviewed, source.Candidates)
+        source.HireRate = percent(source.Hired, source.Candidates)
+        source.AverageScore = round(source.AverageScore)
+        source.AverageDaysToHire = round(source.AverageDaysToHire)
+    }
+    return sources, nil
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+    "cv-extractor/models"
+    "gorm.io/gorm"
+)
+
+// StageDuration summarises the time candidates spent in a stage they entered
+// within the filter's range. A stay is completed once the candidate moved on;
+// ongoing stays are counted but left out of the averages.
+type StageDuration struct {
+    Stage       string  `json:"stage"`
+    Completed   int64   `json:"completed"`
+    Ongoing     int64   `json:"ongoing"`
+    AverageDays float64 `json:"averageDays"`
+    MedianDays  float64 `json:"medianDays"`
+}
+
+// TimeInStage measures how long candidates stay in each stage, from their
+// stage history.
+func TimeInStage(db *gorm.DB, f Filter) ([]StageDuration, error) {
+    // The next change is looked up before the range is applied, so a stay
+    // that started in range but ended after it is still completed.
+    stays := f.candidates(db).
+        Joins("JOIN candidate_stage_histories ON candidate_stage_histories.candidate_id = candidates.id").
+        Select(`candidate_stage_histories.to_stage AS stage,
+            candidate_stage_histories.changed_date AS entered_date,
+            EXTRACT(EPOCH FROM LEAD(candidate_stage_histories.changed_date) OVER (
+                PARTITION BY candidates.id
+                ORDER BY candidate_stage_histories.changed_date, candidate_stage_histories.id
+            ) - candidate_stage_histories.changed_date) / 86400 AS days`)
+
+    var rows []StageDuration
+    if err := f.inRange(db.Table("(?) AS stays", stays), "entered_date").
+        Select(`stage, COUNT(days) AS completed, COUNT(*) - COUNT(days) AS ongoing,
+            COALESCE(AVG(days), 0) AS average_days,
+            COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY days), 0) AS median_days`).
+        Group("stage").
+        Scan(&rows).Error; err != nil {
+        return nil, err
+    }
+
+    byStage := make(map[string]StageDuration, len(rows))
+    for _, row := range rows {
+        byStage[row.Stage] = row
+    }
+    durations := make([]StageDuration, 0, len(models.Stages))
+    for _, stage := range models.Stages {
+        duration := byStage[stage]
+        duration.Stage = stage
+        duration.AverageDays = round(duration.AverageDays)
+        duration.MedianDays = round(duration.MedianDays)
+        durations = append(durations, duration)
+    }
+    return durations, nil
+}
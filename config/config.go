@@ -37,10 +37,14 @@ func InitDB() {
         log.Fatalf("Error pinging database: %v", err)
     }
 
-    if err := db.AutoMigrate(&models.User{}, &models.Company{}, &models.Department{}, &models.Position{}, &models.Candidate{}); err != nil {
+    if err := db.AutoMigrate(&models.User{}, &models.Company{}, &models.Department{}, &models.Position{}, &models.Candidate{}, &models.Tag{}, &models.TalentPool{}, &models.Profile{}, &models.CandidateNote{}, &models.CandidateStageHistory{}, &models.Embedding{}, &models.Skill{}, &models.SkillAlias{}, &models.CandidateSkillExperience{}, &models.CandidateOCRPage{}, &models.CandidateFieldExtraction{}, &models.CandidateFieldReview{}, &models.ExtractionJob{}, &models.CandidateCVVersion{}, &models.StoredFile{}, &models.FileDeletion{}, &models.PositionStatusHistory{}, &models.PositionTeamMember{}); err != nil {
         log.Fatalf("Error during AutoMigrate: %v", err)
     }
 
+    if err := runMigrations(db); err != nil {
+        log.Fatalf("Error during migrations: %v", err)
+    }
+
     DB = db
     fmt.Println("Database connected successfully!")
 }
@@ -0,0 +1,255 @@
+package config
+
+import (
+    "fmt"
+
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "gorm.io/gorm"
+)
+
+// runMigrations applies the data migrations that AutoMigrate cannot express.
+// Every step must be safe to run on each start-up.
+func runMigrations(db *gorm.DB) error {
+    if err := backfillCandidateProfiles(db); err != nil {
+        return fmt.Errorf("backfilling candidate profiles: %v", err)
+    }
+    if err := backfillPositionEducationLevels(db); err != nil {
+        return fmt.Errorf("backfilling position education levels: %v", err)
+    }
+    if err := backfillCVVersions(db); err != nil {
+        return fmt.Errorf("backfilling CV versions: %v", err)
+    }
+    if err := backfillStoredFiles(db); err != nil {
+        return fmt.Errorf("backfilling stored files: %v", err)
+    }
+    if err := setupCVSearch(db); err != nil {
+        return fmt.Errorf("setting up CV search: %v", err)
+    }
+    if err := dropPositionCounters(db); err != nil {
+        return fmt.Errorf("dropping position counters: %v", err)
+    }
+    if err := migratePositionStatuses(db); err != nil {
+        return fmt.Errorf("migrating position statuses: %v", err)
+    }
+    if err := indexProfileNameWords(db); err != nil {
+        return fmt.Errorf("indexing profile name words: %v", err)
+    }
+    if err := backfillScoredDates(db); err != nil {
+        return fmt.Errorf("backfilling scored dates: %v", err)
+    }
+    if err := backfillReviewExtractionVersions(db); err != nil {
+        return fmt.Errorf("backfilling review extraction versions: %v", err)
+    }
+    return nil
+}
+
+// backfillCandidateProfiles links candidates created before profiles existed
+// to a profile, grouping them by company and email address.
+func backfillCandidateProfiles(db *gorm.DB) error {
+    var orphans []struct {
+        ID        uint
+        Name      string
+        Email     string
+        Phone     string
+        CompanyID uint
+    }
+    if err := db.Table("candidates").
+        Select("candidates.id, candidates.name, candidates.email, candidates.phone, departments.company_id").
+        Joins("JOIN positions ON candidates.position_id = positions.id").
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("candidates.profile_id IS NULL").
+        Order("candidates.created_date").
+        Scan(&orphans).Error; err != nil {
+        return err
+    }
+
+    return db.Transaction(func(tx *gorm.DB) error {
+        profileIDs := make(map[string]uint)
+        for _, orphan := range orphans {
+            email := utils.NormalizeEmail(orphan.Email)
+            key := fmt.Sprintf("%d|%s", orphan.CompanyID, email)
+
+            profileID, ok := profileIDs[key]
+            if !ok {
+                var profile models.Profile
+                err := tx.Where("company_id = ? AND email = ?", orphan.CompanyID, email).First(&profile).Error
+                if err == gorm.ErrRecordNotFound {
+                    profile = models.Profile{
+                        Name:           orphan.Name,
+                        NormalizedName: utils.NormalizeName(orphan.Name),
+                        Email:          email,
+                        Phone:          utils.NormalizePhone(orphan.Phone),
+                        CompanyID:      orphan.CompanyID,
+                    }
+                    err = tx.Create(&profile).Error
+                }
+                if err != nil {
+                    return err
+                }
+                profileID = profile.ID
+                profileIDs[key] = profileID
+            }
+
+            if err := tx.Model(&models.Candidate{}).Where("id = ?", orphan.ID).Update("profile_id", profileID).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// backfillPositionEducationLevels parses the free-text education requirement
+// of positions created before education levels existed.
+func backfillPositionEducationLevels(db *gorm.DB) error {
+    var positions []models.Position
+    if err := db.Select("id, education").
+        Where("education_level = ? AND education <> ''", models.EducationUnknown).
+        Find(&positions).Error; err != nil {
+        return err
+    }
+
+    return db.Transaction(func(tx *gorm.DB) error {
+        for _, position := range positions {
+            level := models.ParseEducationRequirement(position.Education)
+            if level == models.EducationUnknown {
+                continue
+            }
+            if err := tx.Model(&models.Position{}).Where("id = ?", position.ID).Update("education_level", level).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// backfillCVVersions records the current CV of candidates created before CV
+// versions existed as their first version.
+func backfillCVVersions(db *gorm.DB) error {
+    var candidates []models.Candidate
+    return db.Where("NOT EXISTS (SELECT 1 FROM candidate_cv_versions WHERE candidate_cv_versions.candidate_id = candidates.id)").
+        FindInBatches(&candidates, 200, func(tx *gorm.DB, batch int) error {
+            versions := make([]models.CandidateCVVersion, 0, len(candidates))
+            for _, candidate := range candidates {
+                versions = append(versions, models.CandidateCVVersion{
+                    CandidateID:  candidate.ID,
+                    Version:      candidate.CVVersion,
+                    CVFile:       candidate.CVFile,
+                    ThumbnailURL: candidate.ThumbnailURL,
+                    CVText:       candidate.CVText,
+                    Fields:       candidate.FieldValues(),
+                    CreatedDate:  candidate.CreatedDate,
+                })
+            }
+            return db.Create(&versions).Error
+        }).Error
+}
+
+// backfillStoredFiles tracks the files CV versions uploaded before content
+// addressing point at, counting one reference per version. They have no
+// hash, so a later identical upload is stored separately.
+func backfillStoredFiles(db *gorm.DB) error {
+    return db.Exec(`INSERT INTO stored_files (object_name, url, size, ref_count, created_date, updated_date)
+SELECT regexp_replace(refs.url, '^https://storage\.googleapis\.com/[^/]+/', ''), refs.url, 0, COUNT(*), NOW(), NOW()
+FROM (
+    SELECT cv_file AS url FROM candidate_cv_versions WHERE cv_file <> ''
+    UNION ALL
+    SELECT thumbnail_url FROM candidate_cv_versions WHERE thumbnail_url <> ''
+) refs
+WHERE NOT EXISTS (SELECT 1 FROM stored_files WHERE stored_files.url = refs.url)
+GROUP BY refs.url`).Error
+}
+
+// setupCVSearch maintains candidates.cv_tsv, the full-text index over a
+// candidate's name, skills and extracted CV text. The column is kept up to
+// date by a trigger so every write path is covered, and is stemmed with the
+// text search configuration stored in cv_text_config.
+func setupCVSearch(db *gorm.DB) error {
+    statements := []string{
+        `ALTER TABLE candidates ADD COLUMN IF NOT EXISTS cv_tsv tsvector`,
+        `CREATE OR REPLACE FUNCTION candidates_cv_tsv_update() RETURNS trigger AS $$
+BEGIN
+    NEW.cv_tsv :=
+        setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector(coalesce(NEW.cv_text_config, 'simple')::regconfig, coalesce(NEW.skills, '')), 'B') ||
+        setweight(to_tsvector(coalesce(NEW.cv_text_config, 'simple')::regconfig, coalesce(NEW.cv_text, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql`,
+        `DROP TRIGGER IF EXISTS candidates_cv_tsv_trigger ON candidates`,
+        `CREATE TRIGGER candidates_cv_tsv_trigger BEFORE INSERT OR UPDATE OF name, skills, cv_text, cv_text_config
+    ON candidates FOR EACH ROW EXECUTE FUNCTION candidates_cv_tsv_update()`,
+        `CREATE INDEX IF NOT EXISTS idx_candidates_cv_tsv ON candidates USING GIN (cv_tsv)`,
+        `UPDATE candidates SET cv_text = cv_text WHERE cv_tsv IS NULL`,
+    }
+
+    for _, statement := range statements {
+        if err := db.Exec(statement).Error; err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// dropPositionCounters removes the per-position CV counters, which were never
+// kept up to date. Position statistics are computed from candidates instead.
+func dropPositionCounters(db *gorm.DB) error {
+    return db.Exec(`ALTER TABLE positions DROP COLUMN IF EXISTS uploaded_cv, DROP COLUMN IF EXISTS filtered_cv`).Error
+}
+
+// migratePositionStatuses replaces the resolved, trash and archive flags of
+// positions created before the position lifecycle with a status, archive
+// taking precedence over trash and trash over resolved, then records each
+// position's status as the start of its history.
+func migratePositionStatuses(db *gorm.DB) error {
+    return db.Transaction(func(tx *gorm.DB) error {
+        if tx.Migrator().HasColumn(&models.Position{}, "is_archive") {
+            if err := tx.Exec(`UPDATE positions SET status = CASE
+    WHEN is_archive THEN ?
+    WHEN is_trash THEN ?
+    WHEN is_resolved THEN ?
+    ELSE ?
+END, status_date = CASE WHEN is_archive OR is_trash THEN COALESCE(removed_date, created_date) ELSE created_date END`,
+                models.PositionArchived, models.PositionClosed, models.PositionFilled, models.PositionOpen).Error; err != nil {
+                return err
+            }
+            if err := tx.Exec(`ALTER TABLE positions DROP COLUMN is_resolved, DROP COLUMN is_trash, DROP COLUMN is_archive, DROP COLUMN IF EXISTS removed_date`).Error; err != nil {
+                return err
+            }
+        }
+
+        return tx.Exec(`INSERT INTO position_status_histories (position_id, from_status, to_status, changed_date)
+SELECT id, '', status, COALESCE(status_date, created_date) FROM positions
+WHERE NOT EXISTS (SELECT 1 FROM position_status_histories WHERE position_status_histories.position_id = positions.id)`).Error
+    })
+}
+
+// indexProfileNameWords indexes the words of profiles' normalized names, so
+// profiles with similar names can be found among those sharing a word rather
+// than by comparing every profile of a company.
+func indexProfileNameWords(db *gorm.DB) error {
+    return db.Exec(`CREATE INDEX IF NOT EXISTS idx_profiles_name_words ON profiles USING GIN (string_to_array(normalized_name, ' '))`).Error
+}
+
+// backfillScoredDates marks candidates scored before scored dates were
+// recorded. A positive score is the only trace such scoring left, so earlier
+// candidates scored 0 stay unscored.
+func backfillScoredDates(db *gorm.DB) error {
+    return db.Exec(`UPDATE candidates SET scored_date = created_date WHERE scored_date IS NULL AND score > 0`).Error
+}
+
+// backfillReviewExtractionVersions records the extraction version and
+// confidence behind reviews made before reviews stored them. Only reviews of
+// the candidate's current CV can be traced to its current extraction; older
+// ones keep version 0.
+func backfillReviewExtractionVersions(db *gorm.DB) error {
+    return db.Exec(`UPDATE candidate_field_reviews
+        SET extraction_version = candidates.extraction_version,
+            extracted_confidence = COALESCE((SELECT candidate_field_extractions.confidence FROM candidate_field_extractions
+                WHERE candidate_field_extractions.candidate_id = candidate_field_reviews.candidate_id
+                AND candidate_field_extractions.field = candidate_field_reviews.field), 0)
+        FROM candidates
+        WHERE candidates.id = candidate_field_reviews.candidate_id
+        AND candidate_field_reviews.cv_version = candidates.cv_version
+        AND candidate_field_reviews.extraction_version = 0`).Error
+}
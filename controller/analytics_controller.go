@@ -0,0 +1,170 @@
+package controller
+
+import (
+    "cv-extractor/analytics"
+    "cv-extractor/config"
+    "cv-extractor/utils"
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// analyticsDateLayout is the format of the from and to query parameters.
+const analyticsDateLayout = "2006-01-02"
+
+// parseAnalyticsFilter reads the report filter from the query string:
+// from and to are inclusive dates defaulting to the last twelve months,
+// interval is day, week or month (the default), and departmentId and
+// positionId narrow the report. Reports always cover the caller's company.
+func parseAnalyticsFilter(c *gin.Context) (analytics.Filter, bool) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    filter := analytics.Filter{
+        CompanyID: userClaims.CompanyID,
+        Interval:  c.DefaultQuery("interval", analytics.IntervalMonth),
+    }
+
+    err := func() error {
+        today := time.Now().UTC().Truncate(24 * time.Hour)
+        to, err := parseAnalyticsDate(c.Query("to"), today)
+        if err != nil {
+            return fmt.Errorf("invalid to: %v", err)
+        }
+        from, err := parseAnalyticsDate(c.Query("from"), to.AddDate(-1, 0, 1))
+        if err != nil {
+            return fmt.Errorf("invalid from: %v", err)
+        }
+        filter.From, filter.To = from, to.AddDate(0, 0, 1)
+
+        if filter.DepartmentID, err = parseAnalyticsID(c.Query("departmentId")); err != nil {
+            return fmt.Errorf("invalid departmentId: %v", err)
+        }
+        if filter.PositionID, err = parseAnalyticsID(c.Query("positionId")); err != nil {
+            return fmt.Errorf("invalid positionId: %v", err)
+        }
+        return filter.Validate()
+    }()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid analytics filter", "details": err.Error()})
+        return filter, false
+    }
+    return filter, true
+}
+
+func parseAnalyticsDate(value string, fallback time.Time) (time.Time, error) {
+    if value == "" {
+        return fallback, nil
+    }
+    return time.Parse(analyticsDateLayout, value)
+}
+
+func parseAnalyticsID(value string) (uint, error) {
+    if value == "" {
+        return 0, nil
+    }
+    id, err := strconv.ParseUint(value, 10, 64)
+    return uint(id), err
+}
+
+// analyticsResponse wraps a report with the range and interval it covers.
+func analyticsResponse(filter analytics.Filter, key string, report interface{}) gin.H {
+    return gin.H{
+        "from":     filter.From.Format(analyticsDateLayout),
+        "to":       filter.To.AddDate(0, 0, -1).Format(analyticsDateLayout),
+        "interval": filter.Interval,
+        key:        report,
+    }
+}
+
+// GetAnalyticsTimeSeries returns applications, hires and time-to-hire per
+// period.
+func GetAnalyticsTimeSeries(c *gin.Context) {
+    filter, ok := parseAnalyticsFilter(c)
+    if !ok {
+        return
+    }
+
+    points, err := analytics.TimeSeries(config.DB, filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute time series", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, analyticsResponse(filter, "series", points))
+}
+
+// GetAnalyticsTimeInStage returns how long candidates stay in each stage.
+func GetAnalyticsTimeInStage(c *gin.Context) {
+    filter, ok := parseAnalyticsFilter(c)
+    if !ok {
+        return
+    }
+
+    durations, err := analytics.TimeInStage(config.DB, filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute time in stage", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, analyticsResponse(filter, "stages", durations))
+}
+
+// GetAnalyticsSourceEffectiveness compares the sources candidates came
+// through.
+func GetAnalyticsSourceEffectiveness(c *gin.Context) {
+    filter, ok := parseAnalyticsFilter(c)
+    if !ok {
+        return
+    }
+
+    sources, err := analytics.SourceEffectiveness(config.DB, filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute source effectiveness", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, analyticsResponse(filter, "sources", sources))
+}
+
+// GetAnalyticsFunnel returns the conversion between the hiring funnel steps.
+func GetAnalyticsFunnel(c *gin.Context) {
+    filter, ok := parseAnalyticsFilter(c)
+    if !ok {
+        return
+    }
+
+    funnel, err := analytics.HiringFunnel(config.DB, filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute funnel", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, analyticsResponse(filter, "funnel", funnel))
+}
+
+// GetAnalyticsBreakdown compares departments, or positions with
+// by=position.
+func GetAnalyticsBreakdown(c *gin.Context) {
+    filter, ok := parseAnalyticsFilter(c)
+    if !ok {
+        return
+    }
+
+    by := c.DefaultQuery("by", analytics.GroupByDepartment)
+    if by != analytics.GroupByDepartment && by != analytics.GroupByPosition {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid analytics filter", "details": "by must be department or position"})
+        return
+    }
+
+    groups, err := analytics.Breakdown(config.DB, filter, by)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute breakdown", "error": err.Error()})
+        return
+    }
+
+    response := analyticsResponse(filter, "groups", groups)
+    response["by"] = by
+    c.JSON(http.StatusOK, response)
+}
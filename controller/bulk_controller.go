@@ -0,0 +1,282 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// Statuses reported for each ID of a bulk operation.
+const (
+    BulkUpdated   = "updated"
+    BulkUnchanged = "unchanged"
+    BulkDeleted   = "deleted"
+    BulkNotFound  = "not_found"
+    BulkFailed    = "failed"
+    BulkSkipped   = "skipped"
+)
+
+// BulkResult reports what a bulk operation did to one of the requested IDs.
+// When the operation is aborted every ID that was not at fault is reported
+// as skipped, since nothing was applied.
+type BulkResult struct {
+    ID     uint   `json:"id"`
+    Status string `json:"status"`
+    Error  string `json:"error,omitempty"`
+}
+
+type BulkMoveCandidateStageInput struct {
+    IDs   []uint `json:"ids" binding:"required"`
+    Stage string `json:"stage" binding:"required"`
+}
+
+type BulkRescoreCandidatesInput struct {
+    IDs []uint `json:"ids" binding:"required"`
+}
+
+type BulkArchivePositionsInput struct {
+    IDs       []uint `json:"ids" binding:"required"`
+    IsArchive *bool  `json:"isArchive" binding:"required"`
+}
+
+// errBulkAborted rolls back a bulk operation whose report already says why.
+var errBulkAborted = errors.New("bulk operation aborted")
+
+// runBulk applies op to every requested item in one transaction and writes
+// the per-ID report. The items are loaded, and their ownership checked, by a
+// single call to load, which returns them by ID; an ID it leaves out is
+// reported as not found, whether it does not exist or belongs to another
+// company. Any missing ID or failing op rolls back the whole operation; an
+// op refused by the position lifecycle is reported as a conflict. It reports
+// whether the operation was committed.
+func runBulk[T any](c *gin.Context, ids []uint, load func(tx *gorm.DB, ids []uint) (map[uint]*T, error), op func(tx *gorm.DB, item *T) (string, error)) bool {
+    ids = uniqueIDs(ids)
+    if len(ids) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "ids must not be empty"})
+        return false
+    }
+
+    results := make([]BulkResult, len(ids))
+    for i, id := range ids {
+        results[i] = BulkResult{ID: id, Status: BulkSkipped}
+    }
+
+    status := http.StatusOK
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        items, err := load(tx, ids)
+        if err != nil {
+            return err
+        }
+
+        for i, id := range ids {
+            if items[id] == nil {
+                results[i].Status = BulkNotFound
+                status = http.StatusNotFound
+            }
+        }
+        if status != http.StatusOK {
+            return errBulkAborted
+        }
+
+        for i, id := range ids {
+            result, err := op(tx, items[id])
+            if err != nil {
+                results[i].Status, results[i].Error = BulkFailed, err.Error()
+                status = http.StatusInternalServerError
+                if errors.Is(err, errInvalidPositionTransition) {
+                    status = http.StatusConflict
+                }
+                return errBulkAborted
+            }
+            results[i].Status = result
+        }
+        return nil
+    })
+
+    switch {
+    case err == nil:
+        c.JSON(http.StatusOK, gin.H{"message": "Bulk operation completed", "results": results})
+        return true
+    case errors.Is(err, errBulkAborted):
+        for i := range results {
+            if results[i].Status != BulkNotFound && results[i].Status != BulkFailed {
+                results[i].Status = BulkSkipped
+            }
+        }
+        c.JSON(status, gin.H{"message": "Bulk operation aborted, no changes were applied", "results": results})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Bulk operation failed", "error": err.Error()})
+    }
+    return false
+}
+
+// companyCandidatesLoader loads the company's candidates for a bulk
+// operation, locking them until it ends.
+func companyCandidatesLoader(companyID uint, preloads ...string) func(tx *gorm.DB, ids []uint) (map[uint]*models.Candidate, error) {
+    return func(tx *gorm.DB, ids []uint) (map[uint]*models.Candidate, error) {
+        query := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "candidates"}})
+        for _, preload := range preloads {
+            query = query.Preload(preload)
+        }
+        candidates, err := findCompanyCandidates(query, companyID, ids)
+        if err != nil {
+            return nil, err
+        }
+
+        byID := make(map[uint]*models.Candidate, len(candidates))
+        for i := range candidates {
+            byID[candidates[i].ID] = &candidates[i]
+        }
+        return byID, nil
+    }
+}
+
+// companyPositionsLoader loads the company's positions for a bulk
+// operation, locking them until it ends.
+func companyPositionsLoader(companyID uint) func(tx *gorm.DB, ids []uint) (map[uint]*models.Position, error) {
+    return func(tx *gorm.DB, ids []uint) (map[uint]*models.Position, error) {
+        var positions []models.Position
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "positions"}}).
+            Joins("JOIN departments ON positions.department_id = departments.id").
+            Where("departments.company_id = ? AND positions.id IN ?", companyID, ids).
+            Find(&positions).Error; err != nil {
+            return nil, err
+        }
+
+        byID := make(map[uint]*models.Position, len(positions))
+        for i := range positions {
+            byID[positions[i].ID] = &positions[i]
+        }
+        return byID, nil
+    }
+}
+
+// BulkDeleteCandidates moves candidates to the trash.
+func BulkDeleteCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input DeleteCandidateInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    runBulk(c, input.IDs, companyCandidatesLoader(userClaims.CompanyID), func(tx *gorm.DB, candidate *models.Candidate) (string, error) {
+        if err := tx.Delete(candidate).Error; err != nil {
+            return "", err
+        }
+        return BulkDeleted, nil
+    })
+}
+
+// BulkQualifyCandidates sets whether candidates are qualified.
+func BulkQualifyCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input QualifyCandidateInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    runBulk(c, input.IDs, companyCandidatesLoader(userClaims.CompanyID), func(tx *gorm.DB, candidate *models.Candidate) (string, error) {
+        if candidate.IsQualified == *input.IsQualified {
+            return BulkUnchanged, nil
+        }
+        if err := tx.Model(candidate).Update("is_qualified", *input.IsQualified).Error; err != nil {
+            return "", err
+        }
+        return BulkUpdated, nil
+    })
+}
+
+// BulkMoveCandidateStage moves candidates to a pipeline stage, recording each
+// change in the candidate's stage history.
+func BulkMoveCandidateStage(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input BulkMoveCandidateStageInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    if !models.IsValidStage(input.Stage) {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown stage", "stages": models.Stages})
+        return
+    }
+
+    runBulk(c, input.IDs, companyCandidatesLoader(userClaims.CompanyID), func(tx *gorm.DB, candidate *models.Candidate) (string, error) {
+        if candidate.Stage == input.Stage {
+            return BulkUnchanged, nil
+        }
+        if err := moveCandidateStage(tx, candidate, input.Stage, userClaims.UserID); err != nil {
+            return "", err
+        }
+        return BulkUpdated, nil
+    })
+}
+
+// BulkRescoreCandidates recomputes the score of candidates against their
+// positions' requirements, replacing scores entered by hand.
+func BulkRescoreCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input BulkRescoreCandidatesInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    runBulk(c, input.IDs, companyCandidatesLoader(userClaims.CompanyID, "Position"), func(tx *gorm.DB, candidate *models.Candidate) (string, error) {
+        score := scoreCandidate(*candidate, candidate.Position, taxonomy).Score
+        if score == candidate.Score && candidate.ScoredDate != nil && !candidate.ScoreManual {
+            return BulkUnchanged, nil
+        }
+        candidate.SetScore(score, time.Now())
+        candidate.ScoreManual = false
+        if err := tx.Model(candidate).Select("score", "scored_date", "score_manual").Updates(candidate).Error; err != nil {
+            return "", err
+        }
+        return BulkUpdated, nil
+    })
+}
+
+// BulkArchivePositions archives filled or closed positions, or returns
+// archived ones to the status they were archived from.
+func BulkArchivePositions(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input BulkArchivePositionsInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    runBulk(c, input.IDs, companyPositionsLoader(userClaims.CompanyID), func(tx *gorm.DB, position *models.Position) (string, error) {
+        archived := position.Status == models.PositionArchived
+        if archived == *input.IsArchive {
+            return BulkUnchanged, nil
+        }
+
+        status := models.PositionArchived
+        if archived {
+            var err error
+            if status, err = unarchivedStatus(tx, position.ID); err != nil {
+                return "", err
+            }
+        }
+        if err := changePositionStatus(tx, position, status, &userClaims.UserID); err != nil {
+            return "", err
+        }
+        return BulkUpdated, nil
+    })
+}
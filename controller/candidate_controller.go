@@ -7,14 +7,16 @@ import (
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
     "net/http"
+    "strings"
     "time"
     "mime/multipart"
 )
 
 type EditCandidateInput struct {
-    Name     string `json:"name" binding:"required"`
-    Email    string `json:"email" binding:"required,email"`
-    Domicile string `json:"domicile" binding:"required"`
+    Name     string  `json:"name" binding:"required"`
+    Email    string  `json:"email" binding:"required,email"`
+    Domicile string  `json:"domicile" binding:"required"`
+    Source   *string `json:"source"`
 }
 
 type ScoreCandidateInput struct {
@@ -24,7 +26,8 @@ type ScoreCandidateInput struct {
 }
 
 type QualifyCandidateInput struct {
-    IDs []uint `json:"ids" binding:"required"`
+    IDs         []uint `json:"ids" binding:"required"`
+    IsQualified *bool  `json:"isQualified" binding:"required"`
 }
 
 type DeleteCandidateInput struct {
@@ -34,38 +37,40 @@ type DeleteCandidateInput struct {
 type CreateCandidateInput struct {
     Name       string `form:"name" binding:"required"`
     Email      string `form:"email" binding:"required,email"`
+    Phone      string `form:"phone"`
     Domicile   string `form:"domicile" binding:"required"`
     PositionID uint   `form:"positionId" binding:"required"`
     CVFile     *multipart.FileHeader `form:"cv_file" binding:"required"`
     Score      float64 `form:"score" binding:"required"`
+    Source     string `form:"source"`
 }
 
-type CandidateFilterInput struct {
-    DepartmentID uint `json:"departmentId"`
-    PositionID   uint `json:"positionId"`
+type MoveCandidateStageInput struct {
+    Stage string `json:"stage" binding:"required"`
 }
 
-
 func CreateCandidate(c *gin.Context) {
     var input CreateCandidateInput
 
     if err := c.ShouldBind(&input); err != nil {
+        if isBodyTooLarge(err) {
+            c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "CV file is too large", "details": err.Error()})
+            return
+        }
         c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid candidate details", "details": err.Error()})
         return
     }
 
     file, err := c.FormFile("cv_file")
     if err != nil {
+        if isBodyTooLarge(err) {
+            c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "CV file is too large", "details": err.Error()})
+            return
+        }
         c.JSON(http.StatusBadRequest, gin.H{"message": "CV file is required", "details": err.Error()})
         return
     }
 
-    fileURL, err := utils.UploadFileToFirebase(file)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to upload CV file", "details": err.Error()})
-        return
-    }
-
     var existingCandidate models.Candidate
     if err := config.DB.Where("email = ? AND position_id = ?", input.Email, input.PositionID).First(&existingCandidate).Error; err == nil {
         c.JSON(http.StatusBadRequest, gin.H{"message": "Candidate already exists"})
@@ -90,68 +95,99 @@ func CreateCandidate(c *gin.Context) {
         return
     }
 
-    newCandidate := models.Candidate{
-        Name:        input.Name,
-        Email:       input.Email,
-        Domicile:    input.Domicile,
-        PositionID:  input.PositionID,
-        CVFile:      fileURL,
-        CreatedDate: time.Now(),
-        Score:       input.Score,
+    if !position.AcceptsApplications(time.Now()) {
+        c.JSON(http.StatusConflict, gin.H{"message": "Position is not accepting applications", "status": position.Status})
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, department.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
     }
 
-    if err := config.DB.Create(&newCandidate).Error; err != nil {
+    upload, ok := storeUploadedCV(c, file)
+    if !ok {
+        return
+    }
+
+    newCandidate := models.Candidate{
+        Name:         input.Name,
+        Email:        input.Email,
+        Phone:        input.Phone,
+        Domicile:     input.Domicile,
+        PositionID:   input.PositionID,
+        CVFile:       upload.FileURL,
+        ThumbnailURL: upload.ThumbnailURL,
+        CVText:       upload.Extraction.Text,
+        CreatedDate:  time.Now(),
+        Stage:        models.StageApplied,
+        Source:       models.NormalizeSource(input.Source),
+    }
+
+    newCandidate.SetScore(input.Score, newCandidate.CreatedDate)
+    applyOCR(&newCandidate, upload.Extraction)
+    applyExtraction(&newCandidate, taxonomy, time.Now())
+
+    var possibleDuplicates []ProfileMatch
+    err = config.DB.Transaction(func(tx *gorm.DB) error {
+        profile, matches, err := resolveProfile(tx, department.CompanyID, input.Name, input.Email, input.Phone)
+        if err != nil {
+            return err
+        }
+        possibleDuplicates = matches
+        newCandidate.ProfileID = &profile.ID
+        if err := tx.Create(&newCandidate).Error; err != nil {
+            return err
+        }
+        if err := createCVVersion(tx, newCandidate, &userClaims.UserID); err != nil {
+            return err
+        }
+        return tx.Create(&models.CandidateStageHistory{
+            CandidateID: newCandidate.ID,
+            ToStage:     newCandidate.Stage,
+            UserID:      &userClaims.UserID,
+        }).Error
+    })
+    if err != nil {
+        discardUploadedCV(upload)
         c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create candidate"})
         return
     }
 
+    refreshEmbedding(models.EmbeddingOwnerCandidate, newCandidate.ID, candidateEmbeddingText(newCandidate))
+
     if err := config.DB.Preload("Position").First(&newCandidate, newCandidate.ID).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load position data", "error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Candidate created successfully", "candidate": newCandidate})
+    c.JSON(http.StatusOK, gin.H{"message": "Candidate created successfully", "candidate": newCandidate, "possibleDuplicates": possibleDuplicates})
 }
 
 func GetAllCandidates(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
 
-    var departments []models.Department
-    if err := config.DB.Where("company_id = ?", userClaims.CompanyID).Find(&departments).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Departments do not exist"})
-        return
-    }
-
-    var departmentIDs []uint
-    for _, dept := range departments {
-        departmentIDs = append(departmentIDs, dept.ID)
-    }
-
-    var positions []models.Position
-    if err := config.DB.Where("department_id IN (?)", departmentIDs).Find(&positions).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Positions do not exist"})
+    params, err := utils.ParseListParams(c, "candidates", candidateSortFields())
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
         return
     }
 
-    var positionIDs []uint
-    for _, pos := range positions {
-        positionIDs = append(positionIDs, pos.ID)
-    }
-
-    var candidates []models.Candidate
-    if err := config.DB.Preload("Position").Where("position_id IN (?)", positionIDs).Find(&candidates).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Candidates do not exist"})
+    page, err := utils.Paginate[models.Candidate](companyCandidatesQuery(userClaims.CompanyID), params, "Position")
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve candidates", "error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, candidates)
+    c.JSON(http.StatusOK, page)
 }
 
 func GetOneCandidate(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
     id := c.Param("id")
     var candidate models.Candidate
-    if err := config.DB.Preload("Position").First(&candidate, id).Error; err != nil {
+    if err := config.DB.Preload("Position").Preload("Tags").Preload("OCRPages").First(&candidate, id).Error; err != nil {
         c.JSON(http.StatusNotFound, gin.H{"message": "Candidate does not exist"})
         return
     }
@@ -197,16 +233,33 @@ func GetCandidatesByPosition(c *gin.Context) {
         return
     }
 
-    var candidates []models.Candidate
-    if err := config.DB.Preload("Position").Preload("Position.Department").Where("position_id = ?", positionID).Find(&candidates).Error; err != nil {
+    params, err := utils.ParseListParams(c, "candidates", candidateSortFields())
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.Candidate{}).Where("position_id = ?", position.ID)
+    page, err := utils.Paginate[models.Candidate](query, params, "Position", "Position.Department")
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"message": "No candidates found for this position"})
         return
     }
 
-    c.JSON(http.StatusOK, candidates)
+    c.JSON(http.StatusOK, page)
 }
 
 func GetCandidatesByFilters(c *gin.Context) {
+    filterCandidates(c, false)
+}
+
+func GetArchivedCandidatesByFilters(c *gin.Context) {
+    filterCandidates(c, true)
+}
+
+// filterCandidates searches the company's candidates of either active or
+// archived positions using the CandidateFilterInput in the request body.
+func filterCandidates(c *gin.Context, archived bool) {
     userClaims := c.MustGet("claims").(*utils.Claims)
     var input CandidateFilterInput
 
@@ -215,51 +268,35 @@ func GetCandidatesByFilters(c *gin.Context) {
         return
     }
 
-    var query = config.DB.Preload("Position").Joins("JOIN positions ON candidates.position_id = positions.id").Joins("JOIN departments ON positions.department_id = departments.id").Where("departments.company_id = ?", userClaims.CompanyID).Where("positions.is_archive = ?", false)
-
-    if input.DepartmentID != 0 {
-        query = query.Where("positions.department_id = ?", input.DepartmentID)
-    }
-
-    if input.PositionID != 0 {
-        query = query.Where("position_id = ?", input.PositionID)
-    }
-
-    var candidates []models.Candidate
-    if err := query.Find(&candidates).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Candidates do not exist"})
+    condition, args, err := input.Where()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid filter", "details": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, candidates)
-}
-
-func GetArchivedCandidatesByFilters(c *gin.Context) {
-    userClaims := c.MustGet("claims").(*utils.Claims)
-    var input CandidateFilterInput
-
-    if err := c.ShouldBindJSON(&input); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+    params, err := utils.ParseListParams(c, "candidates", candidateSortFields())
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
         return
     }
 
-    var query = config.DB.Preload("Position").Joins("JOIN positions ON candidates.position_id = positions.id").Joins("JOIN departments ON positions.department_id = departments.id").Where("departments.company_id = ?", userClaims.CompanyID).Where("positions.is_archive = ?", true)
-
-    if input.DepartmentID != 0 {
-        query = query.Where("positions.department_id = ?", input.DepartmentID)
+    var query = companyCandidatesQuery(userClaims.CompanyID)
+    if archived {
+        query = query.Where("positions.status = ?", models.PositionArchived)
+    } else {
+        query = query.Where("positions.status <> ?", models.PositionArchived)
     }
-
-    if input.PositionID != 0 {
-        query = query.Where("position_id = ?", input.PositionID)
+    if condition != "" {
+        query = query.Where(condition, args...)
     }
 
-    var candidates []models.Candidate
-    if err := query.Find(&candidates).Error; err != nil {
+    page, err := utils.Paginate[models.Candidate](query, params, "Position")
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"message": "Candidates do not exist"})
         return
     }
 
-    c.JSON(http.StatusOK, candidates)
+    c.JSON(http.StatusOK, page)
 }
 
 func EditCandidate(c *gin.Context) {
@@ -294,11 +331,36 @@ func EditCandidate(c *gin.Context) {
         return
     }
 
+    emailChanged := utils.NormalizeEmail(input.Email) != utils.NormalizeEmail(candidate.Email)
     candidate.Name = input.Name
     candidate.Email = input.Email
     candidate.Domicile = input.Domicile
-
-    if err := config.DB.Save(&candidate).Error; err != nil {
+    if input.Source != nil {
+        candidate.Source = models.NormalizeSource(*input.Source)
+    }
+
+    // A new email may belong to another profile, so the candidate is linked
+    // again the way a new application would be.
+    var possibleDuplicates []ProfileMatch
+    previousProfileID := candidate.ProfileID
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        if emailChanged {
+            profile, matches, err := resolveProfile(tx, department.CompanyID, candidate.Name, candidate.Email, candidate.Phone)
+            if err != nil {
+                return err
+            }
+            possibleDuplicates = matches
+            candidate.ProfileID = &profile.ID
+        }
+        if err := tx.Save(&candidate).Error; err != nil {
+            return err
+        }
+        if previousProfileID != nil && *previousProfileID != *candidate.ProfileID {
+            return deleteEmptyProfile(tx, *previousProfileID)
+        }
+        return nil
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update candidate"})
         return
     }
@@ -308,9 +370,12 @@ func EditCandidate(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Candidate updated successfully", "candidate": candidate})
+    c.JSON(http.StatusOK, gin.H{"message": "Candidate updated successfully", "candidate": candidate, "possibleDuplicates": possibleDuplicates})
 }
 
+// ScoreCandidate saves the scores and skills of several candidates. As with
+// the bulk endpoints, every candidate must belong to the caller's company and
+// either all of them are saved or none is, with a per-ID report.
 func ScoreCandidate(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
     var scores []ScoreCandidateInput
@@ -319,56 +384,35 @@ func ScoreCandidate(c *gin.Context) {
         return
     }
 
-    for _, scoreData := range scores {
-        var candidate models.Candidate
-        if err := config.DB.First(&candidate, scoreData.ID).Error; err != nil {
-            c.JSON(http.StatusNotFound, gin.H{"message": "Candidate does not exist"})
-            return
-        }
-
-        var position models.Position
-        if err := config.DB.First(&position, candidate.PositionID).Error; err != nil {
-            c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
-            return
-        }
-
-        var department models.Department
-        if err := config.DB.First(&department, position.DepartmentID).Error; err != nil {
-            c.JSON(http.StatusNotFound, gin.H{"message": "Department does not exist"})
-            return
-        }
-
-        if department.CompanyID != userClaims.CompanyID {
-            c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to score this candidate"})
-            return
-        }
-
-        isQualified := scoreData.Score > 0
-
-        if (candidate.Score == 0 && isQualified) || (candidate.Score > 0 && !isQualified) {
-            var updateValue int
-            if isQualified {
-                updateValue = 1
-            } else {
-                updateValue = -1
-            }
-
-            if err := config.DB.Model(&models.Position{}).Where("id = ?", candidate.PositionID).Update("filtered_cv", gorm.Expr("filtered_cv + ?", updateValue)).Error; err != nil {
-                c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update position"})
-                return
-            }
-        }
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
 
-        candidate.Score = scoreData.Score
-        candidate.Skills = scoreData.Skills
+    ids := make([]uint, len(scores))
+    byID := make(map[uint]ScoreCandidateInput, len(scores))
+    for i, scoreData := range scores {
+        ids[i] = scoreData.ID
+        byID[scoreData.ID] = scoreData
+    }
 
-        if err := config.DB.Save(&candidate).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update candidate"})
-            return
+    var scored []models.Candidate
+    if !runBulk(c, ids, companyCandidatesLoader(userClaims.CompanyID), func(tx *gorm.DB, candidate *models.Candidate) (string, error) {
+        scoreData := byID[candidate.ID]
+        candidate.SetManualScore(scoreData.Score, strings.Join(taxonomy.Normalize(scoreData.Skills), ", "), time.Now())
+        if err := tx.Model(candidate).Select("score", "scored_date", "score_manual", "skills", "skills_manual").Updates(candidate).Error; err != nil {
+            return "", err
         }
+        scored = append(scored, *candidate)
+        return BulkUpdated, nil
+    }) {
+        return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Scores updated successfully"})
+    for _, candidate := range scored {
+        refreshEmbedding(models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate))
+    }
 }
 
 func QualifyCandidate(c *gin.Context) {
@@ -408,6 +452,42 @@ func QualifyCandidate(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": "Qualified status changed successfully"})
 }
 
+// MoveCandidateStage moves a candidate to another pipeline stage and records
+// the change in the candidate's stage history.
+func MoveCandidateStage(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input MoveCandidateStageInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    if !models.IsValidStage(input.Stage) {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown stage", "stages": models.Stages})
+        return
+    }
+
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "move")
+    if !ok {
+        return
+    }
+
+    if candidate.Stage == input.Stage {
+        c.JSON(http.StatusOK, gin.H{"message": "Candidate is already in this stage"})
+        return
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        return moveCandidateStage(tx, &candidate, input.Stage, userClaims.UserID)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to move candidate", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Candidate stage changed successfully", "stage": candidate.Stage})
+}
+
 
 func DeleteCandidate(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
@@ -436,6 +516,8 @@ func DeleteCandidate(c *gin.Context) {
         return
     }
 
+    // The candidate goes to the trash; its files and embedding are kept
+    // until it is purged.
     if err := config.DB.Delete(&candidate).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete candidate"})
         return
@@ -460,3 +542,89 @@ func DeleteAll(c *gin.Context) {
     }
     c.JSON(http.StatusOK, gin.H{"message": "All candidates deleted successfully"})
 }
+
+// findCompanyCandidates loads the candidates with the given IDs that belong to
+// the company. Callers compare the result against the requested IDs to reject
+// requests that reference another company's candidates.
+func findCompanyCandidates(db *gorm.DB, companyID uint, ids []uint) ([]models.Candidate, error) {
+    var candidates []models.Candidate
+    err := db.Joins("JOIN positions ON candidates.position_id = positions.id").
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("departments.company_id = ? AND candidates.id IN ?", companyID, ids).
+        Find(&candidates).Error
+    return candidates, err
+}
+
+func uniqueIDs(ids []uint) []uint {
+    seen := make(map[uint]bool, len(ids))
+    var result []uint
+    for _, id := range ids {
+        if !seen[id] {
+            seen[id] = true
+            result = append(result, id)
+        }
+    }
+    return result
+}
+
+// loadCompanyCandidate loads a candidate and checks that it belongs to the
+// caller's company. When it does not, the error response has already been
+// written and ok is false.
+func loadCompanyCandidate(c *gin.Context, id interface{}, action string) (models.Candidate, bool) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var candidate models.Candidate
+    if err := config.DB.Preload("Position.Department").First(&candidate, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Candidate does not exist"})
+        return candidate, false
+    }
+
+    if candidate.Position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to " + action + " this candidate"})
+        return candidate, false
+    }
+
+    return candidate, true
+}
+
+func candidateSortFields() map[string]utils.SortField {
+    fields := utils.NameAndCreatedSort("candidates")
+    fields["score"] = utils.SortField{Column: "candidates.score", Field: "Score", Kind: utils.SortNumber}
+    return fields
+}
+
+// companyCandidatesQuery selects the candidates of every position in the
+// company, joining positions and departments so callers can filter on them.
+func companyCandidatesQuery(companyID uint) *gorm.DB {
+    return config.DB.Model(&models.Candidate{}).
+        Joins("JOIN positions ON candidates.position_id = positions.id").
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("departments.company_id = ?", companyID)
+}
+
+// moveCandidateStage changes the candidate's stage and appends the change to
+// its stage history. A hire that completes the position's headcount fills
+// the position.
+func moveCandidateStage(tx *gorm.DB, candidate *models.Candidate, stage string, userID uint) error {
+    history := models.CandidateStageHistory{
+        CandidateID: candidate.ID,
+        FromStage:   candidate.Stage,
+        ToStage:     stage,
+        UserID:      &userID,
+    }
+    if err := tx.Model(candidate).Update("stage", stage).Error; err != nil {
+        return err
+    }
+    if err := tx.Create(&history).Error; err != nil {
+        return err
+    }
+    if stage != models.StageHired {
+        return nil
+    }
+
+    position, err := lockPosition(tx, candidate.PositionID)
+    if err != nil {
+        return err
+    }
+    return autoClosePosition(tx, &position, time.Now())
+}
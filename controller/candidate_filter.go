@@ -0,0 +1,172 @@
+package controller
+
+import (
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// maxFilterDepth bounds how deeply filter groups may be nested.
+const maxFilterDepth = 5
+
+// candidateHasSkill matches candidates whose skills list has an entry equal
+// to the argument, a skill normalized with utils.NormalizeSkill. Entries are
+// split and normalized the way utils.SplitSkills and utils.NormalizeSkill do,
+// so "Go" matches "Go" but not "MongoDB" or "Django".
+const candidateHasSkill = `? = ANY (SELECT btrim(regexp_replace(lower(entry), '\s+', ' ', 'g'), ' .,;:-()[]"''')
+    FROM regexp_split_to_table(candidates.skills, '[,;|\n•]') AS entry)`
+
+// CandidateFilterInput is a group of candidate search conditions. Conditions
+// within a group are combined with Operator ("and" by default, or "or"), and
+// nested Groups are combined with the group's other conditions the same way,
+// so arbitrary AND/OR expressions can be built.
+type CandidateFilterInput struct {
+    DepartmentID       uint                    `json:"departmentId"`
+    PositionID         uint                    `json:"positionId"`
+    MinScore           *float64                `json:"minScore"`
+    MaxScore           *float64                `json:"maxScore"`
+    IsQualified        *bool                   `json:"isQualified"`
+    Stages             []string                `json:"stages"`
+    Domicile           string                  `json:"domicile"`
+    CreatedFrom        *time.Time              `json:"createdFrom"`
+    CreatedTo          *time.Time              `json:"createdTo"`
+    SkillsAll          []string                `json:"skillsAll"`
+    SkillsAny          []string                `json:"skillsAny"`
+    MinEducation       models.EducationLevel   `json:"minEducation"`
+    MinExperienceYears *float64                `json:"minExperienceYears"`
+    MaxExperienceYears *float64                `json:"maxExperienceYears"`
+    SkillExperience    []SkillExperienceFilter `json:"skillExperience"`
+    TagIDs             []uint                  `json:"tagIds"`
+    Text               string                  `json:"text"`
+    Operator           string                  `json:"operator"`
+    Groups             []CandidateFilterInput  `json:"groups"`
+}
+
+// SkillExperienceFilter matches candidates whose work history shows at least
+// MinYears of experience with Skill.
+type SkillExperienceFilter struct {
+    Skill    string  `json:"skill"`
+    MinYears float64 `json:"minYears"`
+}
+
+// Where builds the SQL condition for the filter, qualified against the
+// candidates, positions and departments tables. An empty filter yields an
+// empty condition.
+func (f CandidateFilterInput) Where() (string, []interface{}, error) {
+    return f.build(0)
+}
+
+func (f CandidateFilterInput) build(depth int) (string, []interface{}, error) {
+    if depth > maxFilterDepth {
+        return "", nil, fmt.Errorf("filter groups may be nested at most %d levels deep", maxFilterDepth)
+    }
+
+    var joiner string
+    switch strings.ToLower(f.Operator) {
+    case "", "and":
+        joiner = " AND "
+    case "or":
+        joiner = " OR "
+    default:
+        return "", nil, fmt.Errorf("operator must be \"and\" or \"or\"")
+    }
+
+    var conditions []string
+    var args []interface{}
+    add := func(condition string, values ...interface{}) {
+        conditions = append(conditions, condition)
+        args = append(args, values...)
+    }
+
+    if f.DepartmentID != 0 {
+        add("positions.department_id = ?", f.DepartmentID)
+    }
+    if f.PositionID != 0 {
+        add("candidates.position_id = ?", f.PositionID)
+    }
+    if f.MinScore != nil {
+        add("candidates.score >= ?", *f.MinScore)
+    }
+    if f.MaxScore != nil {
+        add("candidates.score <= ?", *f.MaxScore)
+    }
+    if f.IsQualified != nil {
+        add("candidates.is_qualified = ?", *f.IsQualified)
+    }
+    if len(f.Stages) > 0 {
+        for _, stage := range f.Stages {
+            if !models.IsValidStage(stage) {
+                return "", nil, fmt.Errorf("unknown stage %q", stage)
+            }
+        }
+        add("candidates.stage IN ?", f.Stages)
+    }
+    if f.Domicile != "" {
+        add("candidates.domicile ILIKE ?", containsPattern(f.Domicile))
+    }
+    if f.CreatedFrom != nil {
+        add("candidates.created_date >= ?", *f.CreatedFrom)
+    }
+    if f.CreatedTo != nil {
+        add("candidates.created_date <= ?", *f.CreatedTo)
+    }
+    if len(f.SkillsAll) > 0 {
+        var parts []string
+        for _, skill := range f.SkillsAll {
+            parts = append(parts, candidateHasSkill)
+            args = append(args, utils.NormalizeSkill(skill))
+        }
+        conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+    }
+    if len(f.SkillsAny) > 0 {
+        var parts []string
+        for _, skill := range f.SkillsAny {
+            parts = append(parts, candidateHasSkill)
+            args = append(args, utils.NormalizeSkill(skill))
+        }
+        conditions = append(conditions, "("+strings.Join(parts, " OR ")+")")
+    }
+    if f.MinEducation != models.EducationUnknown {
+        add("candidates.education_level >= ?", f.MinEducation)
+    }
+    if f.MinExperienceYears != nil {
+        add("candidates.experience_months >= ?", *f.MinExperienceYears*12)
+    }
+    if f.MaxExperienceYears != nil {
+        add("candidates.experience_months <= ?", *f.MaxExperienceYears*12)
+    }
+    for _, experience := range f.SkillExperience {
+        add("EXISTS (SELECT 1 FROM candidate_skill_experiences WHERE candidate_skill_experiences.candidate_id = candidates.id AND LOWER(candidate_skill_experiences.skill) = LOWER(?) AND candidate_skill_experiences.months >= ?)",
+            strings.TrimSpace(experience.Skill), experience.MinYears*12)
+    }
+    if len(f.TagIDs) > 0 {
+        add("EXISTS (SELECT 1 FROM candidate_tags WHERE candidate_tags.candidate_id = candidates.id AND candidate_tags.tag_id IN ?)", f.TagIDs)
+    }
+    if f.Text != "" {
+        pattern := containsPattern(f.Text)
+        add("(candidates.name ILIKE ? OR candidates.email ILIKE ? OR candidates.domicile ILIKE ? OR candidates.skills ILIKE ?)",
+            pattern, pattern, pattern, pattern)
+    }
+
+    for _, group := range f.Groups {
+        condition, groupArgs, err := group.build(depth + 1)
+        if err != nil {
+            return "", nil, err
+        }
+        if condition != "" {
+            conditions = append(conditions, "("+condition+")")
+            args = append(args, groupArgs...)
+        }
+    }
+
+    return strings.Join(conditions, joiner), args, nil
+}
+
+// containsPattern turns user input into an ILIKE pattern matching it anywhere,
+// escaping the LIKE wildcards it may contain.
+func containsPattern(s string) string {
+    s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.TrimSpace(s))
+    return "%" + s + "%"
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "reflect"
+    "regexp"
+    "strings"
+    "testing"
+)
+
+func TestCandidateFilterWhere(t *testing.T) {
+    minScore := 80.0
+    qualified := true
+
+    tests := []struct {
+        name      string
+        filter    CandidateFilterInput
+        condition string
+        args      []interface{}
+    }{
+        {name: "empty"},
+        {
+            name:      "conditions are joined with AND by default",
+            filter:    CandidateFilterInput{PositionID: 7, IsQualified: &qualified},
+            condition: "candidates.position_id = ? AND candidates.is_qualified = ?",
+            args:      []interface{}{uint(7), true},
+        },
+        {
+            name:      "skills are normalized",
+            filter:    CandidateFilterInput{SkillsAll: []string{" Go.", "SQL"}, SkillsAny: []string{"Docker"}},
+            condition: "(" + candidateHasSkill + " AND " + candidateHasSkill + ") AND (" + candidateHasSkill + ")",
+            args:      []interface{}{"go", "sql", "docker"},
+        },
+        {
+            name: "nested groups keep their operator and argument order",
+            filter: CandidateFilterInput{
+                PositionID: 7,
+                Groups: []CandidateFilterInput{
+                    {
+                        Operator: "OR",
+                        Stages:   []string{models.StageApplied},
+                        Domicile: "Jakarta",
+                        Groups:   []CandidateFilterInput{{MinScore: &minScore, IsQualified: &qualified}},
+                    },
+                    {},
+                    {Text: "50%"},
+                },
+            },
+            condition: "candidates.position_id = ? AND " +
+                "(candidates.stage IN ? OR candidates.domicile ILIKE ? OR (candidates.score >= ? AND candidates.is_qualified = ?)) AND " +
+                "((candidates.name ILIKE ? OR candidates.email ILIKE ? OR candidates.domicile ILIKE ? OR candidates.skills ILIKE ?))",
+            args: []interface{}{uint(7), []string{models.StageApplied}, "%Jakarta%", 80.0, true,
+                `%50\%%`, `%50\%%`, `%50\%%`, `%50\%%`},
+        },
+    }
+    for _, test := range tests {
+        condition, args, err := test.filter.Where()
+        if err != nil {
+            t.Errorf("%s: Where: %v", test.name, err)
+            continue
+        }
+        if condition != test.condition {
+            t.Errorf("%s: condition = %q, want %q", test.name, condition, test.condition)
+        }
+        if !reflect.DeepEqual(args, test.args) {
+            t.Errorf("%s: args = %#v, want %#v", test.name, args, test.args)
+        }
+    }
+}
+
+func TestCandidateFilterWhereErrors(t *testing.T) {
+    nested := func(depth int) CandidateFilterInput {
+        filter := CandidateFilterInput{PositionID: 1}
+        for i := 0; i < depth; i++ {
+            filter = CandidateFilterInput{Groups: []CandidateFilterInput{filter}}
+        }
+        return filter
+    }
+
+    if _, _, err := nested(maxFilterDepth).Where(); err != nil {
+        t.Errorf("Where rejected groups nested %d levels deep: %v", maxFilterDepth, err)
+    }
+
+    tests := []struct {
+        name   string
+        filter CandidateFilterInput
+    }{
+        {name: "too deep", filter: nested(maxFilterDepth + 1)},
+        {name: "unknown operator", filter: CandidateFilterInput{Operator: "xor"}},
+        {name: "unknown stage", filter: CandidateFilterInput{Stages: []string{"interviewing"}}},
+        {name: "error in a group", filter: CandidateFilterInput{Groups: []CandidateFilterInput{{Operator: "not"}}}},
+    }
+    for _, test := range tests {
+        if _, _, err := test.filter.Where(); err == nil {
+            t.Errorf("%s: Where succeeded, want an error", test.name)
+        }
+    }
+}
+
+// TestCandidateHasSkillMatchesNormalizeSkill checks that the SQL splits and
+// trims skill entries the way utils.SplitSkills and utils.NormalizeSkill do,
+// so a normalized skill argument can equal a normalized entry.
+func TestCandidateHasSkillMatchesNormalizeSkill(t *testing.T) {
+    trim := regexp.MustCompile(`, '((?:[^']|'')*)'\)\s+FROM`).FindStringSubmatch(candidateHasSkill)
+    split := regexp.MustCompile(`regexp_split_to_table\(candidates\.skills, '\[([^\]]*)\]'\)`).FindStringSubmatch(candidateHasSkill)
+    if trim == nil || split == nil {
+        t.Fatalf("candidateHasSkill no longer has the expected btrim and regexp_split_to_table calls")
+    }
+    trimSet := strings.ReplaceAll(trim[1], "''", "'")
+    splitSet := strings.ReplaceAll(split[1], `\n`, "\n")
+
+    // Whitespace is collapsed to single spaces before trimming.
+    if !strings.ContainsRune(trimSet, ' ') {
+        t.Errorf("SQL trim set %q does not trim spaces", trimSet)
+    }
+    for r := rune('!'); r <= '~'; r++ {
+        trimmed := utils.NormalizeSkill("x"+string(r)) == "x"
+        if inSQL := strings.ContainsRune(trimSet, r); inSQL != trimmed {
+            t.Errorf("%q: trimmed in SQL = %v, by NormalizeSkill = %v", r, inSQL, trimmed)
+        }
+    }
+
+    for _, r := range "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~\n\t•" {
+        splits := len(utils.SplitSkills("a"+string(r)+"b")) == 2
+        if inSQL := strings.ContainsRune(splitSet, r); inSQL != splits {
+            t.Errorf("%q: splits in SQL = %v, in SplitSkills = %v", r, inSQL, splits)
+        }
+    }
+}
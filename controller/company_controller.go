@@ -69,13 +69,19 @@ func GetOneCompany(c *gin.Context) {
 }
 
 func GetAllCompanies(c *gin.Context) {
-    var companies []models.Company
-    if err := config.DB.Find(&companies).Error; err != nil {
+    params, err := utils.ParseListParams(c, "companies", utils.NameAndCreatedSort("companies"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    page, err := utils.Paginate[models.Company](config.DB.Model(&models.Company{}), params)
+    if err != nil {
         log.Printf("Failed to retrieve companies: %v\n", err)
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve companies"})
         return
     }
-    c.JSON(http.StatusOK, companies)
+    c.JSON(http.StatusOK, page)
 }
 
 func EditCompany(c *gin.Context) {
@@ -164,43 +170,47 @@ func DeleteCompany(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": "Company deleted successfully"})
 }
 
+// deleteRelatedData permanently deletes the company's departments, positions
+// and candidates, including those in the trash, together with the profiles,
+// tags and talent pools it keeps about them and its skills taxonomy.
 func deleteRelatedData(tx *gorm.DB, companyID uint) error {
     var departments []models.Department
-    if err := tx.Where("company_id = ?", companyID).Find(&departments).Error; err != nil {
+    if err := tx.Unscoped().Where("company_id = ?", companyID).Find(&departments).Error; err != nil {
         return err
     }
 
     for _, department := range departments {
         var positions []models.Position
-        if err := tx.Where("department_id = ?", department.ID).Find(&positions).Error; err != nil {
+        if err := tx.Unscoped().Where("department_id = ?", department.ID).Find(&positions).Error; err != nil {
             return err
         }
 
         for _, position := range positions {
-            var candidates []models.Candidate
-            if err := tx.Where("position_id = ?", position.ID).Find(&candidates).Error; err != nil {
+            var candidateIDs []uint
+            if err := tx.Unscoped().Model(&models.Candidate{}).Where("position_id = ?", position.ID).Pluck("id", &candidateIDs).Error; err != nil {
                 return err
             }
-
-            for _, candidate := range candidates {
-                if err := utils.DeleteFileFromFirebase(candidate.CVFile); err != nil {
-                    return err
-                }
-
-                if err := tx.Delete(&candidate).Error; err != nil {
-                    return err
-                }
+            if err := purgeCandidates(tx, candidateIDs); err != nil {
+                return err
             }
 
-            if err := tx.Delete(&position).Error; err != nil {
+            if err := deleteEmbeddings(tx, models.EmbeddingOwnerPosition, []uint{position.ID}); err != nil {
+                return err
+            }
+            if err := tx.Unscoped().Delete(&position).Error; err != nil {
                 return err
             }
         }
 
-        if err := tx.Delete(&department).Error; err != nil {
+        if err := tx.Unscoped().Delete(&department).Error; err != nil {
             return err
         }
     }
 
+    for _, model := range []interface{}{&models.Profile{}, &models.Tag{}, &models.TalentPool{}, &models.SkillAlias{}, &models.Skill{}} {
+        if err := tx.Where("company_id = ?", companyID).Delete(model).Error; err != nil {
+            return err
+        }
+    }
     return nil
 }
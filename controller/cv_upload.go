@@ -0,0 +1,152 @@
+package controller
+
+import (
+    "context"
+    "cv-extractor/config"
+    "cv-extractor/extractor"
+    "cv-extractor/filestore"
+    "cv-extractor/models"
+    "cv-extractor/ocr"
+    "cv-extractor/scanner"
+    "cv-extractor/thumbnail"
+    "cv-extractor/upload"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "mime/multipart"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// uploadedCV is a CV file that has been stored, with its thumbnail and the
+// text extracted from it.
+type uploadedCV struct {
+    FileURL      string
+    ThumbnailURL string
+    Extraction   extractor.Extraction
+}
+
+// storeUploadedCV checks and stores an uploaded CV file, then extracts its
+// text and renders its thumbnail. The file must fit the size limit, its
+// content must be a supported format matching its extension, and it must
+// pass the malware scanner when one is configured. Extraction and thumbnail
+// failures are logged rather than rejected, since the CV itself was stored.
+// When it returns false the error response has been written.
+func storeUploadedCV(c *gin.Context, file *multipart.FileHeader) (uploadedCV, bool) {
+    maxSize := upload.MaxCVSize()
+    tooLarge := fmt.Sprintf("CV files must not exceed %d MB", maxSize>>20)
+    if file.Size > maxSize {
+        c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "CV file is too large", "details": tooLarge})
+        return uploadedCV{}, false
+    }
+
+    f, err := file.Open()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Failed to read CV file", "details": err.Error()})
+        return uploadedCV{}, false
+    }
+    defer f.Close()
+
+    // The checks and the upload read the file as a stream, each from the
+    // start, so a file that gets rejected is never held in memory.
+    format, err := upload.Validate(file.Filename, f, file.Size)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid CV file", "details": err.Error()})
+        return uploadedCV{}, false
+    }
+
+    if s := scanner.Default(); s != nil {
+        result, err := s.Scan(c.Request.Context(), io.NewSectionReader(f, 0, file.Size))
+        if err != nil {
+            log.Printf("Failed to scan CV %s with %s: %v", file.Filename, s.Name(), err)
+            c.JSON(http.StatusServiceUnavailable, gin.H{"message": "CV file could not be scanned for malware, please try again later"})
+            return uploadedCV{}, false
+        }
+        if !result.Clean {
+            log.Printf("Rejected CV %s: %s found by %s", file.Filename, result.Signature, s.Name())
+            c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "CV file was rejected by the malware scanner", "details": result.Signature})
+            return uploadedCV{}, false
+        }
+    }
+
+    stored, err := filestore.StoreFile(config.DB, "cv_files", upload.Extension(format), upload.ContentType(format), io.NewSectionReader(f, 0, file.Size))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to upload CV file", "details": err.Error()})
+        return uploadedCV{}, false
+    }
+
+    // Text extraction and thumbnails parse the whole document, so only a
+    // file that passed the checks and was stored is read into memory.
+    data, err := io.ReadAll(io.NewSectionReader(f, 0, file.Size))
+    if err != nil {
+        discardUploadedCV(uploadedCV{FileURL: stored.URL})
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Failed to read CV file", "details": err.Error()})
+        return uploadedCV{}, false
+    }
+
+    result := uploadedCV{FileURL: stored.URL}
+    result.Extraction, err = extractor.Extract(c.Request.Context(), data, ocr.Default())
+    if err != nil {
+        log.Printf("Failed to extract text from CV %s: %v", file.Filename, err)
+    }
+
+    result.ThumbnailURL, err = uploadThumbnail(c.Request.Context(), data)
+    if err != nil {
+        log.Printf("Failed to generate thumbnail of CV %s: %v", file.Filename, err)
+    }
+
+    return result, true
+}
+
+// isBodyTooLarge reports whether parsing a request failed because its body
+// exceeded the limit set by middleware.BodyLimit.
+func isBodyTooLarge(err error) bool {
+    var maxBytesErr *http.MaxBytesError
+    return errors.As(err, &maxBytesErr)
+}
+
+// uploadThumbnail stores a preview of the first page of a CV next to the CV
+// itself so lists can show it without downloading the full file.
+func uploadThumbnail(ctx context.Context, data []byte) (string, error) {
+    thumbnailData, err := thumbnail.Generate(ctx, data)
+    if err != nil {
+        return "", err
+    }
+
+    stored, err := filestore.Store(config.DB, "thumbnails", ".png", "image/png", thumbnailData)
+    if err != nil {
+        return "", err
+    }
+    return stored.URL, nil
+}
+
+// discardUploadedCV releases the files stored for a CV that ended up not
+// being saved to any candidate.
+func discardUploadedCV(cv uploadedCV) {
+    if err := filestore.Release(config.DB, cv.FileURL, cv.ThumbnailURL); err != nil {
+        log.Printf("Failed to release files of discarded CV %s: %v", cv.FileURL, err)
+    }
+}
+
+// releaseCandidateFiles drops the references the CV versions of the given
+// candidates hold on their files, ahead of deleting the candidates. Files
+// left unreferenced are deleted from storage once tx commits.
+func releaseCandidateFiles(tx *gorm.DB, candidateIDs []uint) error {
+    if len(candidateIDs) == 0 {
+        return nil
+    }
+
+    var versions []models.CandidateCVVersion
+    if err := tx.Select("cv_file", "thumbnail_url").Where("candidate_id IN ?", candidateIDs).Find(&versions).Error; err != nil {
+        return err
+    }
+
+    urls := make([]string, 0, 2*len(versions))
+    for _, version := range versions {
+        urls = append(urls, version.CVFile, version.ThumbnailURL)
+    }
+    return filestore.Release(tx, urls...)
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+type FieldDiff struct {
+    Field   string   `json:"field"`
+    From    string   `json:"from"`
+    To      string   `json:"to"`
+    Changed bool     `json:"changed"`
+    Added   []string `json:"added,omitempty"`
+    Removed []string `json:"removed,omitempty"`
+}
+
+// UploadCandidateCV replaces a candidate's CV with a new version. The
+// previous file stays in storage and in the version history, and the new CV
+// is extracted and scored from scratch.
+func UploadCandidateCV(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    file, err := c.FormFile("cv_file")
+    if err != nil {
+        if isBodyTooLarge(err) {
+            c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "CV file is too large", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusBadRequest, gin.H{"message": "CV file is required", "details": err.Error()})
+        return
+    }
+
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "upload a CV for")
+    if !ok {
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    upload, ok := storeUploadedCV(c, file)
+    if !ok {
+        return
+    }
+
+    err = config.DB.Transaction(func(tx *gorm.DB) error {
+        // Reload under a lock so concurrent uploads get distinct versions.
+        locked, err := lockCandidate(tx, candidate.ID)
+        if err != nil {
+            return err
+        }
+        candidate = locked
+
+        candidate.CVVersion++
+        candidate.CVFile = upload.FileURL
+        candidate.ThumbnailURL = upload.ThumbnailURL
+        candidate.CVText = upload.Extraction.Text
+        applyOCR(&candidate, upload.Extraction)
+        applyExtraction(&candidate, taxonomy, time.Now())
+        candidate.SetScore(scoreCandidate(candidate, candidate.Position, taxonomy).Score, time.Now())
+        candidate.ScoreManual = false
+        candidate.SkillsManual = false
+
+        if err := tx.Model(&candidate).
+            Select("cv_version", "cv_file", "thumbnail_url", "cv_text", "from_ocr", "language", "cv_text_config", "skills",
+                "education_level", "experience_months", "extraction_version", "extraction_confidence", "review_status", "score", "scored_date",
+                "score_manual", "skills_manual").
+            Updates(&candidate).Error; err != nil {
+            return err
+        }
+
+        if err := tx.Where("candidate_id = ?", candidate.ID).Delete(&models.CandidateOCRPage{}).Error; err != nil {
+            return err
+        }
+        for i := range candidate.OCRPages {
+            candidate.OCRPages[i].CandidateID = candidate.ID
+        }
+        if len(candidate.OCRPages) > 0 {
+            if err := tx.Create(&candidate.OCRPages).Error; err != nil {
+                return err
+            }
+        }
+
+        if err := replaceExtractionRecords(tx, &candidate); err != nil {
+            return err
+        }
+        return createCVVersion(tx, candidate, &userClaims.UserID)
+    })
+    if err != nil {
+        discardUploadedCV(upload)
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to save CV version", "error": err.Error()})
+        return
+    }
+
+    refreshEmbedding(models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate))
+
+    c.JSON(http.StatusOK, gin.H{"message": "CV uploaded successfully", "candidate": candidate})
+}
+
+// lockCandidate loads a candidate and its position, locking the candidate row
+// until the transaction ends.
+func lockCandidate(tx *gorm.DB, id uint) (models.Candidate, error) {
+    var candidate models.Candidate
+    if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&candidate, id).Error; err != nil {
+        return candidate, err
+    }
+    err := tx.First(&candidate.Position, candidate.PositionID).Error
+    return candidate, err
+}
+
+func GetCandidateCVVersions(c *gin.Context) {
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "view CV versions of")
+    if !ok {
+        return
+    }
+
+    var versions []models.CandidateCVVersion
+    if err := config.DB.Where("candidate_id = ?", candidate.ID).Order("version DESC").Find(&versions).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve CV versions", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, versions)
+}
+
+// GetCandidateCVDiff compares the extracted fields of two CV versions, given
+// by the from and to query parameters. They default to the current version
+// and the one before it.
+func GetCandidateCVDiff(c *gin.Context) {
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "view CV versions of")
+    if !ok {
+        return
+    }
+
+    to, err := strconv.Atoi(c.DefaultQuery("to", strconv.Itoa(candidate.CVVersion)))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "to must be a version number"})
+        return
+    }
+    from, err := strconv.Atoi(c.DefaultQuery("from", strconv.Itoa(to-1)))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "from must be a version number"})
+        return
+    }
+
+    var versions []models.CandidateCVVersion
+    if err := config.DB.Where("candidate_id = ? AND version IN ?", candidate.ID, []int{from, to}).Find(&versions).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve CV versions", "error": err.Error()})
+        return
+    }
+
+    byVersion := make(map[int]models.CandidateCVVersion, len(versions))
+    for _, version := range versions {
+        byVersion[version.Version] = version
+    }
+    fromVersion, fromOK := byVersion[from]
+    toVersion, toOK := byVersion[to]
+    if !fromOK || !toOK {
+        c.JSON(http.StatusNotFound, gin.H{"message": "CV version does not exist"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"from": fromVersion, "to": toVersion, "fields": diffFieldValues(fromVersion.Fields, toVersion.Fields)})
+}
+
+// diffFieldValues compares every extracted field. Skills are also compared
+// as sets so that the diff lists the skills added and removed.
+func diffFieldValues(from, to models.FieldValues) []FieldDiff {
+    diffs := make([]FieldDiff, 0, len(models.ExtractedFields))
+    for _, field := range models.ExtractedFields {
+        diff := FieldDiff{Field: field, From: from[field], To: to[field], Changed: from[field] != to[field]}
+        if field == models.FieldSkills {
+            diff.Added = skillsMissingFrom(to[field], from[field])
+            diff.Removed = skillsMissingFrom(from[field], to[field])
+        }
+        diffs = append(diffs, diff)
+    }
+    return diffs
+}
+
+// skillsMissingFrom returns the skills of list that other does not contain.
+func skillsMissingFrom(list, other string) []string {
+    present := make(map[string]bool)
+    for _, skill := range utils.SplitSkills(other) {
+        present[utils.NormalizeSkill(skill)] = true
+    }
+
+    var missing []string
+    for _, skill := range utils.SplitSkills(list) {
+        if !present[utils.NormalizeSkill(skill)] {
+            missing = append(missing, strings.TrimSpace(skill))
+        }
+    }
+    return missing
+}
+
+// createCVVersion records the candidate's current CV and extracted fields as
+// version candidate.CVVersion.
+func createCVVersion(tx *gorm.DB, candidate models.Candidate, userID *uint) error {
+    return tx.Create(&models.CandidateCVVersion{
+        CandidateID:  candidate.ID,
+        Version:      candidate.CVVersion,
+        CVFile:       candidate.CVFile,
+        ThumbnailURL: candidate.ThumbnailURL,
+        CVText:       candidate.CVText,
+        Fields:       candidate.FieldValues(),
+        UserID:       userID,
+    }).Error
+}
+
+// replaceExtractionRecords replaces the stored field extractions and skill
+// experience of a candidate with those applyExtraction just computed.
+func replaceExtractionRecords(tx *gorm.DB, candidate *models.Candidate) error {
+    if err := tx.Where("candidate_id = ?", candidate.ID).Delete(&models.CandidateFieldExtraction{}).Error; err != nil {
+        return err
+    }
+    for i := range candidate.FieldExtractions {
+        candidate.FieldExtractions[i].CandidateID = candidate.ID
+    }
+    if len(candidate.FieldExtractions) > 0 {
+        if err := tx.Create(&candidate.FieldExtractions).Error; err != nil {
+            return err
+        }
+    }
+
+    if err := tx.Where("candidate_id = ?", candidate.ID).Delete(&models.CandidateSkillExperience{}).Error; err != nil {
+        return err
+    }
+    for i := range candidate.SkillExperience {
+        candidate.SkillExperience[i].CandidateID = candidate.ID
+    }
+    if len(candidate.SkillExperience) > 0 {
+        return tx.Create(&candidate.SkillExperience).Error
+    }
+    return nil
+}
@@ -5,8 +5,10 @@ import (
     "cv-extractor/models"
     "cv-extractor/utils"
     "net/http"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
 )
 
 type CreateDepartmentInput struct {
@@ -54,18 +56,25 @@ func CreateDepartment(c *gin.Context) {
 func GetAllDepartments(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
 
-    var departments []models.Department
-    if err := config.DB.Where("company_id = ?", userClaims.CompanyID).Preload("Positions").Find(&departments).Error; err != nil {
+    params, err := utils.ParseListParams(c, "departments", utils.NameAndCreatedSort("departments"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.Department{}).Where("departments.company_id = ?", userClaims.CompanyID)
+    page, err := utils.Paginate[models.Department](query, params, "Positions")
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
         return
     }
 
-    if len(departments) == 0 {
+    if page.Total == 0 {
         c.JSON(http.StatusNotFound, gin.H{"message": "Department is empty"})
         return
     }
 
-    c.JSON(http.StatusOK, departments)
+    c.JSON(http.StatusOK, page)
 }
 
 func GetOneDepartment(c *gin.Context) {
@@ -151,38 +160,20 @@ func DeleteDepartment(c *gin.Context) {
         return
     }
 
-    var positions []models.Position
-    if err := config.DB.Where("department_id = ?", department.ID).Find(&positions).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
-        return
-    }
-
-    for _, position := range positions {
-        var candidates []models.Candidate
-        if err := config.DB.Where("position_id = ?", position.ID).Find(&candidates).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
-            return
+    // The department goes to the trash with its positions and candidates,
+    // all stamped with the same time so they can be restored together.
+    deletedAt := time.Now()
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        var positionIDs []uint
+        if err := tx.Model(&models.Position{}).Where("department_id = ?", department.ID).Pluck("id", &positionIDs).Error; err != nil {
+            return err
         }
-
-        for _, candidate := range candidates {
-            if err := utils.DeleteFileFromFirebase(candidate.CVFile); err != nil {
-                c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
-                return
-            }
-
-            if err := config.DB.Delete(&candidate).Error; err != nil {
-                c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
-                return
-            }
-        }
-
-        if err := config.DB.Delete(&position).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
-            return
+        if err := trashPositions(tx, positionIDs, deletedAt); err != nil {
+            return err
         }
-    }
-
-    if err := config.DB.Delete(&department).Error; err != nil {
+        return tx.Model(&department).Update("deleted_at", deletedAt).Error
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"message": "Server Error", "error": err.Error()})
         return
     }
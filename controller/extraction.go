@@ -0,0 +1,162 @@
+package controller
+
+import (
+    "cv-extractor/extractor"
+    "cv-extractor/models"
+    "math"
+    "sort"
+    "strings"
+    "time"
+)
+
+// extractionVersion identifies the rules applyExtraction implements. Bump it
+// whenever they change so that candidates processed by older rules can be
+// re-extracted. Candidates extracted before versioning have version 0.
+const extractionVersion = 1
+
+// reviewConfidenceThreshold is the confidence below which an extracted field
+// sends its candidate to the review queue.
+const reviewConfidenceThreshold = 0.6
+
+// Confidences of values depending on where in the CV they were found.
+const (
+    confidenceInSection = 0.9
+    confidenceInText    = 0.6
+    confidenceNotFound  = 0.3
+)
+
+// applyOCR flags a candidate whose CV text was recognized by OCR and keeps
+// the engine's confidence for each page.
+func applyOCR(candidate *models.Candidate, extraction extractor.Extraction) {
+    candidate.FromOCR = len(extraction.OCRPages) > 0
+    candidate.OCRPages = ocrPages(extraction)
+}
+
+func ocrPages(extraction extractor.Extraction) []models.CandidateOCRPage {
+    pages := make([]models.CandidateOCRPage, 0, len(extraction.OCRPages))
+    for _, page := range extraction.OCRPages {
+        pages = append(pages, models.CandidateOCRPage{
+            PageNumber: page.Number,
+            Engine:     extraction.OCREngine,
+            Confidence: page.Confidence,
+        })
+    }
+    return pages
+}
+
+// applyExtraction fills the fields of a candidate that are derived from its
+// CV text: its language, the taxonomy skills it mentions, the highest degree
+// it lists and the experience computed from its work history. Each field's
+// raw value and confidence is recorded, and candidates with a field below
+// reviewConfidenceThreshold are queued for review. Skills are not rated for
+// companies without a skills taxonomy.
+func applyExtraction(candidate *models.Candidate, taxonomy skillTaxonomy, now time.Time) {
+    text := candidate.CVText
+    sections := extractor.Sections(text)
+    candidate.ExtractionVersion = extractionVersion
+    confidences := make(map[string]float64)
+
+    candidate.Language, confidences[models.FieldLanguage] = extractor.DetectLanguageConfidence(text)
+    candidate.CVTextConfig = extractor.SearchConfig(candidate.Language)
+
+    skills := taxonomy.Find(text)
+    candidate.Skills = strings.Join(skills, ", ")
+    if !taxonomy.Empty() {
+        // Without a taxonomy no skills can be found, which says nothing
+        // about the CV, so the field is not rated.
+        confidences[models.FieldSkills] = sectionConfidence(len(skills) > 0, len(taxonomy.Find(sections[extractor.SectionSkills])) > 0)
+    }
+
+    candidate.EducationLevel, confidences[models.FieldEducationLevel] = educationLevel(sections, text)
+
+    periods := extractor.ParseWorkHistory(text, candidate.Language, now)
+    candidate.ExperienceMonths = extractor.TotalMonths(periods)
+    candidate.SkillExperience = skillExperience(periods, taxonomy)
+    confidences[models.FieldExperienceMonths] = sectionConfidence(len(periods) > 0, strings.TrimSpace(sections[extractor.SectionExperience]) != "")
+
+    // Recognition errors carry over to everything parsed from OCR text.
+    if candidate.FromOCR {
+        ocrConfidence := meanOCRConfidence(candidate.OCRPages)
+        for field := range confidences {
+            confidences[field] *= ocrConfidence
+        }
+    }
+
+    candidate.FieldExtractions = nil
+    candidate.ExtractionConfidence = 1
+    for _, field := range models.ExtractedFields {
+        confidence, rated := confidences[field]
+        if !rated {
+            continue
+        }
+        candidate.FieldExtractions = append(candidate.FieldExtractions, models.CandidateFieldExtraction{
+            Field:      field,
+            Value:      candidate.FieldValue(field),
+            Confidence: math.Round(confidence*100) / 100,
+        })
+        candidate.ExtractionConfidence = math.Min(candidate.ExtractionConfidence, confidence)
+    }
+    candidate.ExtractionConfidence = math.Round(candidate.ExtractionConfidence*100) / 100
+
+    candidate.ReviewStatus = models.ReviewNotRequired
+    if candidate.ExtractionConfidence < reviewConfidenceThreshold {
+        candidate.ReviewStatus = models.ReviewPending
+    }
+}
+
+// sectionConfidence rates a value by where it was found: in its own section
+// of the CV, elsewhere in the text, or not at all.
+func sectionConfidence(found, inSection bool) float64 {
+    switch {
+    case found && inSection:
+        return confidenceInSection
+    case found:
+        return confidenceInText
+    default:
+        return confidenceNotFound
+    }
+}
+
+func meanOCRConfidence(pages []models.CandidateOCRPage) float64 {
+    if len(pages) == 0 {
+        return 0
+    }
+    var sum float64
+    for _, page := range pages {
+        sum += page.Confidence
+    }
+    return sum / float64(len(pages))
+}
+
+// skillExperience attributes each work period to the taxonomy skills its
+// description mentions and totals the months per skill.
+func skillExperience(periods []extractor.WorkPeriod, taxonomy skillTaxonomy) []models.CandidateSkillExperience {
+    periodsBySkill := make(map[string][]extractor.WorkPeriod)
+    for _, period := range periods {
+        for _, skill := range taxonomy.Find(period.Text) {
+            periodsBySkill[skill] = append(periodsBySkill[skill], period)
+        }
+    }
+
+    experience := make([]models.CandidateSkillExperience, 0, len(periodsBySkill))
+    for skill, skillPeriods := range periodsBySkill {
+        experience = append(experience, models.CandidateSkillExperience{
+            Skill:  skill,
+            Months: extractor.TotalMonths(skillPeriods),
+        })
+    }
+    sort.Slice(experience, func(i, j int) bool { return experience[i].Skill < experience[j].Skill })
+    return experience
+}
+
+// educationLevel reads the highest degree from the education section, or
+// from the whole CV when it has no recognizable education heading. Limiting
+// the search avoids mistaking job titles like "Scrum Master" for degrees.
+func educationLevel(sections map[string]string, text string) (models.EducationLevel, float64) {
+    if section := sections[extractor.SectionEducation]; strings.TrimSpace(section) != "" {
+        level := models.ParseEducationLevel(section)
+        return level, sectionConfidence(level != models.EducationUnknown, true)
+    }
+    level := models.ParseEducationLevel(text)
+    return level, sectionConfidence(level != models.EducationUnknown, false)
+}
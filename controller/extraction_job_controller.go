@@ -0,0 +1,358 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// extractionJobBatchSize is the number of candidates re-extracted between
+// progress updates of a job.
+const extractionJobBatchSize = 100
+
+// extractionJobLease is how long a running job may go without a heartbeat,
+// sent with every progress update, before another process takes it over. It
+// is also how often interrupted jobs are looked for.
+const extractionJobLease = 10 * time.Minute
+
+// ErrExtractionJobClaimed is returned for a job that another run has already
+// claimed, or that is no longer pending.
+var ErrExtractionJobClaimed = errors.New("extraction job is already claimed")
+
+type CreateExtractionJobInput struct {
+    PositionID *uint `json:"positionId"`
+    MinVersion *int  `json:"minVersion"`
+    MaxVersion *int  `json:"maxVersion"`
+}
+
+// CreateExtractionJob queues a re-extraction of the company's candidates and
+// runs it in the background. The job can be narrowed to one position and to
+// candidates last processed by extraction versions between minVersion and
+// maxVersion inclusive.
+func CreateExtractionJob(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input CreateExtractionJobInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    job := models.ExtractionJob{
+        CompanyID:  userClaims.CompanyID,
+        PositionID: input.PositionID,
+        MinVersion: input.MinVersion,
+        MaxVersion: input.MaxVersion,
+        Status:     models.JobQueued,
+        UserID:     &userClaims.UserID,
+    }
+    if status, err := validateExtractionJob(config.DB, job); err != nil {
+        c.JSON(status, gin.H{"message": err.Error()})
+        return
+    }
+
+    if err := config.DB.Create(&job).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create job", "error": err.Error()})
+        return
+    }
+
+    go func() {
+        if err := RunExtractionJob(job.ID); err != nil && err != ErrExtractionJobClaimed {
+            log.Printf("Extraction job %d failed: %v", job.ID, err)
+        }
+    }()
+
+    c.JSON(http.StatusAccepted, gin.H{"message": "Extraction job queued", "job": job})
+}
+
+func GetAllExtractionJobs(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    sortFields := map[string]utils.SortField{
+        "created": {Column: "extraction_jobs.created_date", Field: "CreatedDate", Kind: utils.SortTime},
+    }
+    params, err := utils.ParseListParams(c, "extraction_jobs", sortFields)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.ExtractionJob{}).Where("company_id = ?", userClaims.CompanyID)
+    page, err := utils.Paginate[models.ExtractionJob](query, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve jobs", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func GetOneExtractionJob(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var job models.ExtractionJob
+    if err := config.DB.First(&job, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Job does not exist"})
+        return
+    }
+
+    if job.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this job"})
+        return
+    }
+
+    c.JSON(http.StatusOK, job)
+}
+
+// validateExtractionJob checks that the job's position belongs to its
+// company and that its version range is well formed.
+func validateExtractionJob(db *gorm.DB, job models.ExtractionJob) (int, error) {
+    if job.MinVersion != nil && job.MaxVersion != nil && *job.MinVersion > *job.MaxVersion {
+        return http.StatusBadRequest, fmt.Errorf("minVersion must not exceed maxVersion")
+    }
+
+    if job.PositionID != nil {
+        var position models.Position
+        if err := db.Preload("Department").First(&position, *job.PositionID).Error; err != nil {
+            return http.StatusNotFound, fmt.Errorf("Position does not exist")
+        }
+        if position.Department.CompanyID != job.CompanyID {
+            return http.StatusForbidden, fmt.Errorf("You do not have access to this position")
+        }
+    }
+    return http.StatusOK, nil
+}
+
+// NewExtractionJob creates a queued job, validated as through the API. It is
+// used by the reextract command.
+func NewExtractionJob(companyID uint, positionID *uint, minVersion, maxVersion *int) (models.ExtractionJob, error) {
+    job := models.ExtractionJob{
+        CompanyID:  companyID,
+        PositionID: positionID,
+        MinVersion: minVersion,
+        MaxVersion: maxVersion,
+        Status:     models.JobQueued,
+    }
+    if _, err := validateExtractionJob(config.DB, job); err != nil {
+        return job, err
+    }
+    return job, config.DB.Create(&job).Error
+}
+
+// RunExtractionJobResumer resumes interrupted extraction jobs at start-up
+// and then periodically until the process exits.
+func RunExtractionJobResumer() {
+    for {
+        ResumeExtractionJobs()
+        time.Sleep(extractionJobLease)
+    }
+}
+
+// ResumeExtractionJobs restarts the jobs that are still queued or whose run
+// stopped sending heartbeats, such as those running when a server stopped.
+// Re-extraction is idempotent, so running a job again from the start is safe.
+func ResumeExtractionJobs() {
+    var ids []uint
+    if err := config.DB.Model(&models.ExtractionJob{}).
+        Where("status IN ?", []string{models.JobQueued, models.JobRunning}).
+        Order("id").
+        Pluck("id", &ids).Error; err != nil {
+        log.Printf("Failed to load pending extraction jobs: %v", err)
+        return
+    }
+
+    for _, id := range ids {
+        if err := RunExtractionJob(id); err != nil && err != ErrExtractionJobClaimed {
+            log.Printf("Extraction job %d failed: %v", id, err)
+        }
+    }
+}
+
+// RunExtractionJob re-extracts and rescores every candidate in the job's
+// scope, recording progress on the job as it goes. A candidate that fails is
+// counted and skipped; the job only fails when it cannot proceed at all. It
+// returns ErrExtractionJobClaimed without doing anything when the job is
+// neither queued nor abandoned by an earlier run.
+func RunExtractionJob(jobID uint) error {
+    claimed, err := claimExtractionJob(jobID, time.Now())
+    if err != nil {
+        return err
+    }
+    if !claimed {
+        return ErrExtractionJobClaimed
+    }
+
+    var job models.ExtractionJob
+    if err := config.DB.First(&job, jobID).Error; err != nil {
+        return err
+    }
+
+    err = processExtractionJob(&job)
+
+    finished := time.Now()
+    job.FinishedDate = &finished
+    job.Status = models.JobCompleted
+    if err != nil {
+        job.Status, job.Error = models.JobFailed, err.Error()
+    }
+    if saveErr := config.DB.Save(&job).Error; saveErr != nil && err == nil {
+        err = saveErr
+    }
+    return err
+}
+
+// claimExtractionJob atomically marks a queued job, or a running one whose
+// heartbeat is older than extractionJobLease, as running from now, so that
+// concurrent runs of the same job cannot both start it.
+func claimExtractionJob(jobID uint, now time.Time) (bool, error) {
+    result := config.DB.Model(&models.ExtractionJob{}).
+        Where("id = ? AND (status = ? OR (status = ? AND (heartbeat_date IS NULL OR heartbeat_date < ?)))",
+            jobID, models.JobQueued, models.JobRunning, now.Add(-extractionJobLease)).
+        Updates(map[string]interface{}{
+            "status":         models.JobRunning,
+            "started_date":   now,
+            "heartbeat_date": now,
+            "finished_date":  nil,
+            "error":          "",
+            "processed":      0,
+            "failed":         0,
+        })
+    return result.RowsAffected > 0, result.Error
+}
+
+func processExtractionJob(job *models.ExtractionJob) error {
+    taxonomy, err := loadSkillTaxonomy(config.DB, job.CompanyID)
+    if err != nil {
+        return err
+    }
+
+    var total int64
+    if err := extractionJobQuery(*job).Count(&total).Error; err != nil {
+        return err
+    }
+    job.Total = int(total)
+    if err := config.DB.Model(job).Update("total", job.Total).Error; err != nil {
+        return err
+    }
+
+    // Candidates are visited in ID order so that those already re-extracted,
+    // whose version may now fall outside the range, are never revisited.
+    var lastID uint
+    for {
+        var ids []uint
+        if err := extractionJobQuery(*job).
+            Where("candidates.id > ?", lastID).
+            Order("candidates.id").
+            Limit(extractionJobBatchSize).
+            Pluck("candidates.id", &ids).Error; err != nil {
+            return err
+        }
+        if len(ids) == 0 {
+            return nil
+        }
+
+        for _, id := range ids {
+            var candidate models.Candidate
+            err := config.DB.Transaction(func(tx *gorm.DB) error {
+                var err error
+                candidate, err = reextractCandidate(tx, id, taxonomy, time.Now())
+                return err
+            })
+            if err != nil {
+                log.Printf("Failed to re-extract candidate %d in job %d: %v", id, job.ID, err)
+                job.Failed++
+                continue
+            }
+            refreshEmbedding(models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate))
+            job.Processed++
+        }
+        lastID = ids[len(ids)-1]
+
+        heartbeat := time.Now()
+        job.HeartbeatDate = &heartbeat
+        if err := config.DB.Model(job).Select("processed", "failed", "heartbeat_date").Updates(job).Error; err != nil {
+            return err
+        }
+    }
+}
+
+func extractionJobQuery(job models.ExtractionJob) *gorm.DB {
+    query := companyCandidatesQuery(job.CompanyID)
+    if job.PositionID != nil {
+        query = query.Where("candidates.position_id = ?", *job.PositionID)
+    }
+    if job.MinVersion != nil {
+        query = query.Where("candidates.extraction_version >= ?", *job.MinVersion)
+    }
+    if job.MaxVersion != nil {
+        query = query.Where("candidates.extraction_version <= ?", *job.MaxVersion)
+    }
+    return query
+}
+
+// reextractCandidate runs extraction again on a candidate's stored CV text
+// and rescores it against its position. Fields a recruiter corrected keep the
+// corrected value, and a reviewed candidate stays reviewed; the raw field
+// extractions are replaced with the new parser output.
+func reextractCandidate(tx *gorm.DB, id uint, taxonomy skillTaxonomy, now time.Time) (models.Candidate, error) {
+    var candidate models.Candidate
+    if err := tx.Preload("Position").Preload("OCRPages").First(&candidate, id).Error; err != nil {
+        return candidate, err
+    }
+
+    var corrections []models.CandidateFieldReview
+    if err := tx.Where("candidate_id = ? AND cv_version = ? AND corrected", id, candidate.CVVersion).Find(&corrections).Error; err != nil {
+        return candidate, err
+    }
+
+    previous := candidate
+    applyExtraction(&candidate, taxonomy, now)
+    for _, correction := range corrections {
+        restoreField(&candidate, previous, correction.Field)
+    }
+    if previous.SkillsManual {
+        restoreField(&candidate, previous, models.FieldSkills)
+    }
+    if previous.ReviewStatus == models.ReviewReviewed {
+        candidate.ReviewStatus = models.ReviewReviewed
+    }
+    if !previous.ScoreManual {
+        candidate.SetScore(scoreCandidate(candidate, candidate.Position, taxonomy).Score, now)
+    }
+
+    if err := tx.Model(&candidate).
+        Select("language", "cv_text_config", "skills", "education_level", "experience_months",
+            "extraction_version", "extraction_confidence", "review_status", "score", "scored_date").
+        Updates(&candidate).Error; err != nil {
+        return candidate, err
+    }
+
+    if err := replaceExtractionRecords(tx, &candidate); err != nil {
+        return candidate, err
+    }
+
+    return candidate, nil
+}
+
+// restoreField copies a corrected field from the candidate as it was before
+// re-extraction.
+func restoreField(candidate *models.Candidate, previous models.Candidate, field string) {
+    switch field {
+    case models.FieldLanguage:
+        candidate.Language = previous.Language
+        candidate.CVTextConfig = previous.CVTextConfig
+    case models.FieldSkills:
+        candidate.Skills = previous.Skills
+    case models.FieldEducationLevel:
+        candidate.EducationLevel = previous.EducationLevel
+    case models.FieldExperienceMonths:
+        candidate.ExperienceMonths = previous.ExperienceMonths
+    }
+}
@@ -0,0 +1,287 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/embedding"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "log"
+    "net/http"
+    "sort"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    // embeddingBackfillInterval is how often missing embeddings are
+    // computed.
+    embeddingBackfillInterval = 5 * time.Minute
+    // embeddingBackfillBatch is how many rows are embedded per query.
+    embeddingBackfillBatch = 100
+)
+
+type CandidateMatch struct {
+    Candidate  models.Candidate `json:"candidate"`
+    Similarity float64          `json:"similarity"`
+}
+
+// GetMatchingCandidates ranks candidates by semantic similarity to a
+// position's requirements. By default only the position's own candidates are
+// ranked; scope=company ranks every candidate of the company.
+func GetMatchingCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    limit, offset, err := utils.ParseOffsetParams(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    var position models.Position
+    if err := config.DB.Preload("Department").First(&position, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
+        return
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this position"})
+        return
+    }
+
+    target, err := positionVector(config.DB, position)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to embed position", "error": err.Error()})
+        return
+    }
+
+    query := companyCandidatesQuery(userClaims.CompanyID)
+    if c.Query("scope") != "company" {
+        query = query.Where("candidates.position_id = ?", position.ID)
+    }
+
+    page, err := rankCandidates(config.DB, query, target, limit, offset)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to rank candidates", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+// GetSimilarCandidates ranks the company's other candidates by how similar
+// their CVs are to the given candidate's.
+func GetSimilarCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    limit, offset, err := utils.ParseOffsetParams(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "view")
+    if !ok {
+        return
+    }
+
+    target, err := ownerVector(config.DB, models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to embed candidate", "error": err.Error()})
+        return
+    }
+
+    query := companyCandidatesQuery(userClaims.CompanyID).Where("candidates.id != ?", candidate.ID)
+    page, err := rankCandidates(config.DB, query, target, limit, offset)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to rank candidates", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+// rankCandidates ranks the candidates of query by the similarity of their
+// stored vectors to target and returns the requested page. Only IDs and
+// vectors are read for the ranking, and the candidates themselves only for
+// the page. Candidates without a vector from the current provider are left
+// out until the backfill worker embeds them.
+func rankCandidates(db *gorm.DB, query *gorm.DB, target models.Vector, limit, offset int) (utils.Page, error) {
+    var rows []struct {
+        ID     uint
+        Vector models.Vector
+    }
+    if err := query.
+        Joins("JOIN embeddings ON embeddings.owner_type = ? AND embeddings.owner_id = candidates.id AND embeddings.provider = ?",
+            models.EmbeddingOwnerCandidate, embedding.Default().Name()).
+        Select("candidates.id, embeddings.vector").
+        Scan(&rows).Error; err != nil {
+        return utils.Page{}, err
+    }
+
+    similarities := make(map[uint]float64, len(rows))
+    ids := make([]uint, len(rows))
+    for i, row := range rows {
+        similarities[row.ID] = embedding.Cosine(target, row.Vector)
+        ids[i] = row.ID
+    }
+    sort.SliceStable(ids, func(i, j int) bool { return similarities[ids[i]] > similarities[ids[j]] })
+
+    total := int64(len(ids))
+    ids = ids[min(offset, len(ids)):min(offset+limit, len(ids))]
+
+    var candidates []models.Candidate
+    if len(ids) > 0 {
+        if err := db.Preload("Position").Where("id IN ?", ids).Find(&candidates).Error; err != nil {
+            return utils.Page{}, err
+        }
+    }
+    byID := make(map[uint]models.Candidate, len(candidates))
+    for _, candidate := range candidates {
+        byID[candidate.ID] = candidate
+    }
+
+    matches := make([]CandidateMatch, 0, len(ids))
+    for _, id := range ids {
+        if candidate, ok := byID[id]; ok {
+            matches = append(matches, CandidateMatch{Candidate: candidate, Similarity: similarities[id]})
+        }
+    }
+    return utils.OffsetPage(matches, total, limit, offset), nil
+}
+
+func candidateEmbeddingText(candidate models.Candidate) string {
+    return candidate.Skills + "\n" + candidate.CVText
+}
+
+func positionEmbeddingText(position models.Position) string {
+    return position.Name + "\n" + position.Description + "\n" + position.Qualification
+}
+
+// positionVector returns the vector of a position's requirements.
+func positionVector(db *gorm.DB, position models.Position) (models.Vector, error) {
+    return ownerVector(db, models.EmbeddingOwnerPosition, position.ID, positionEmbeddingText(position))
+}
+
+// ownerVector returns the stored vector of an owner, or computes it from
+// text without storing it when the backfill worker has not embedded the
+// owner yet, so reads never write.
+func ownerVector(db *gorm.DB, ownerType string, ownerID uint, text string) (models.Vector, error) {
+    provider := embedding.Default()
+    var record models.Embedding
+    err := db.Where("owner_type = ? AND owner_id = ? AND provider = ?", ownerType, ownerID, provider.Name()).
+        First(&record).Error
+    if err == nil {
+        return record.Vector, nil
+    }
+    if err != gorm.ErrRecordNotFound {
+        return nil, err
+    }
+    return provider.Embed(text)
+}
+
+// saveEmbedding computes the vector of text with the default provider and
+// stores it for the owner, replacing any previous vector.
+func saveEmbedding(db *gorm.DB, ownerType string, ownerID uint, text string) (models.Vector, error) {
+    provider := embedding.Default()
+    vector, err := provider.Embed(text)
+    if err != nil {
+        return nil, err
+    }
+
+    record := models.Embedding{
+        OwnerType: ownerType,
+        OwnerID:   ownerID,
+        Provider:  provider.Name(),
+        Vector:    vector,
+    }
+    err = db.Clauses(clause.OnConflict{
+        Columns:   []clause.Column{{Name: "owner_type"}, {Name: "owner_id"}, {Name: "provider"}},
+        DoUpdates: clause.AssignmentColumns([]string{"vector", "updated_date"}),
+    }).Create(&record).Error
+    return vector, err
+}
+
+// refreshEmbedding recomputes an owner's vector after its text changed. A
+// failure leaves the previous vector in place and is only logged, so it never
+// fails the request that changed the text.
+func refreshEmbedding(ownerType string, ownerID uint, text string) {
+    if _, err := saveEmbedding(config.DB, ownerType, ownerID, text); err != nil {
+        log.Printf("Failed to update %s %d embedding: %v", ownerType, ownerID, err)
+    }
+}
+
+// RunEmbeddingBackfill embeds the candidates and positions that have no
+// vector from the current provider, such as those created before it was
+// configured or whose refresh failed, every few minutes until the process
+// exits.
+func RunEmbeddingBackfill() {
+    for {
+        embedded, err := BackfillEmbeddings()
+        if err != nil {
+            log.Printf("Failed to backfill embeddings: %v", err)
+        } else if embedded > 0 {
+            log.Printf("Embedded %d candidates and positions", embedded)
+        }
+        time.Sleep(embeddingBackfillInterval)
+    }
+}
+
+// BackfillEmbeddings stores vectors for every candidate and position missing
+// one from the current provider and returns how many it embedded.
+func BackfillEmbeddings() (int, error) {
+    provider := embedding.Default().Name()
+    missing := func(model interface{}, table, ownerType string) *gorm.DB {
+        return config.DB.Model(model).
+            Where("NOT EXISTS (SELECT 1 FROM embeddings WHERE embeddings.owner_type = ? AND embeddings.owner_id = "+table+".id AND embeddings.provider = ?)", ownerType, provider).
+            Order("id").
+            Limit(embeddingBackfillBatch)
+    }
+
+    embedded := 0
+    for {
+        var candidates []models.Candidate
+        if err := missing(&models.Candidate{}, "candidates", models.EmbeddingOwnerCandidate).
+            Select("id", "skills", "cv_text").Find(&candidates).Error; err != nil {
+            return embedded, err
+        }
+        for _, candidate := range candidates {
+            if _, err := saveEmbedding(config.DB, models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate)); err != nil {
+                return embedded, err
+            }
+            embedded++
+        }
+        if len(candidates) < embeddingBackfillBatch {
+            break
+        }
+    }
+
+    for {
+        var positions []models.Position
+        if err := missing(&models.Position{}, "positions", models.EmbeddingOwnerPosition).
+            Select("id", "name", "description", "qualification").Find(&positions).Error; err != nil {
+            return embedded, err
+        }
+        for _, position := range positions {
+            if _, err := saveEmbedding(config.DB, models.EmbeddingOwnerPosition, position.ID, positionEmbeddingText(position)); err != nil {
+                return embedded, err
+            }
+            embedded++
+        }
+        if len(positions) < embeddingBackfillBatch {
+            break
+        }
+    }
+    return embedded, nil
+}
+
+// deleteEmbeddings removes the stored vectors of the given owners.
+func deleteEmbeddings(db *gorm.DB, ownerType string, ownerIDs []uint) error {
+    if len(ownerIDs) == 0 {
+        return nil
+    }
+    return db.Where("owner_type = ? AND owner_id IN ?", ownerType, ownerIDs).Delete(&models.Embedding{}).Error
+}
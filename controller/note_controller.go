@@ -0,0 +1,56 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+type CreateCandidateNoteInput struct {
+    Body string `json:"body" binding:"required"`
+}
+
+func CreateCandidateNote(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input CreateCandidateNoteInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "add notes to")
+    if !ok {
+        return
+    }
+
+    note := models.CandidateNote{
+        CandidateID: candidate.ID,
+        UserID:      &userClaims.UserID,
+        Body:        input.Body,
+    }
+
+    if err := config.DB.Create(&note).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create note", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Note created successfully", "note": note})
+}
+
+func GetCandidateNotes(c *gin.Context) {
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "view notes of")
+    if !ok {
+        return
+    }
+
+    var notes []models.CandidateNote
+    if err := config.DB.Where("candidate_id = ?", candidate.ID).Order("created_date").Find(&notes).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve notes", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, notes)
+}
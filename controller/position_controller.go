@@ -7,6 +7,7 @@ import (
 	"cv-extractor/utils"
 	"cv-extractor/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"log"
 )
 
@@ -18,6 +19,9 @@ type CreatePositionInput struct {
 	Description   string `json:"description" binding:"required"`
 	Qualification string `json:"qualification" binding:"required"`
 	DepartmentID  uint   `json:"departmentId" binding:"required"`
+	Status        string `json:"status" binding:"omitempty,oneof=draft open"`
+	PositionOpeningInput
+	HiringTeamInput
 }
 
 type EditPositionInput struct {
@@ -67,25 +71,66 @@ func CreatePosition(c *gin.Context) {
 		return
 	}
 
+	valid, err := input.HiringTeamInput.checkCompanyUsers(config.DB, userClaims.CompanyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check users", "details": err.Error()})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hiring team members must be users of your company"})
+		return
+	}
+
 	now := time.Now()
 
+	status := models.PositionOpen
+	if input.Status != "" {
+		status = input.Status
+	}
+
 	position := models.Position{
-		Name:          input.Name,
-		Education:     input.Education,
-		Location:      input.Location,
-		MinWorkExp:    input.MinWorkExp,
-		Description:   input.Description,
-		Qualification: input.Qualification,
-		DepartmentID:  input.DepartmentID,
-		CreatedDate:   now,
+		Name:           input.Name,
+		Education:      input.Education,
+		EducationLevel: models.ParseEducationRequirement(input.Education),
+		Location:       input.Location,
+		MinWorkExp:     input.MinWorkExp,
+		Description:    input.Description,
+		Qualification:  input.Qualification,
+		DepartmentID:   input.DepartmentID,
+		CreatedDate:     now,
+		Status:         status,
+		StatusDate:     now,
+	}
+	if err := input.PositionOpeningInput.apply(&position); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
 	}
 
-	if err := config.DB.Create(&position).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&position).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PositionStatusHistory{
+			PositionID:  position.ID,
+			ToStatus:    position.Status,
+			UserID:      &userClaims.UserID,
+			ChangedDate: now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := replaceHiringTeam(tx, position.ID, input.HiringTeamInput); err != nil {
+			return err
+		}
+		return autoClosePosition(tx, &position, now)
+	})
+	if err != nil {
 		log.Printf("Failed to create position: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create position"})
 		return
 	}
 
+	refreshEmbedding(models.EmbeddingOwnerPosition, position.ID, positionEmbeddingText(position))
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Position created successfully",
 		"position": position,
@@ -95,31 +140,35 @@ func CreatePosition(c *gin.Context) {
 func GetAllPositions(c *gin.Context) {
 	userClaims := c.MustGet("claims").(*utils.Claims)
 
-	var departments []models.Department
-	if err := config.DB.Where("company_id = ?", userClaims.CompanyID).Find(&departments).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": "Departments do not exist"})
+	params, err := utils.ParseListParams(c, "positions", utils.NameAndCreatedSort("positions"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
 		return
 	}
 
-	var departmentIDs []uint
-	for _, dept := range departments {
-		departmentIDs = append(departmentIDs, dept.ID)
+	query := companyPositionsQuery(userClaims.CompanyID)
+	if status := c.Query("status"); status != "" {
+		if !models.IsValidPositionStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown status", "statuses": models.PositionStatuses})
+			return
+		}
+		query = query.Where("positions.status = ?", status)
 	}
 
-	var positions []models.Position
-	if err := config.DB.Preload("Department").Where("department_id IN (?)", departmentIDs).Find(&positions).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Positions do not exist"})
-        return
-    }
+	page, err := utils.Paginate[models.Position](query, params, "Department")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Positions do not exist"})
+		return
+	}
 
-	c.JSON(http.StatusOK, positions)
+	c.JSON(http.StatusOK, page)
 }
 
 func GetOnePosition(c *gin.Context) {
 	userClaims := c.MustGet("claims").(*utils.Claims)
 	id := c.Param("id")
 	var position models.Position
-	if err := config.DB.First(&position, id).Error; err != nil {
+	if err := config.DB.Preload("HiringTeam").First(&position, id).Error; err != nil {
 		log.Printf("Position not found: %v\n", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
 		return
@@ -168,6 +217,7 @@ func EditPosition(c *gin.Context) {
 
 	position.Name = input.Name
 	position.Education = input.Education
+	position.EducationLevel = models.ParseEducationRequirement(input.Education)
 	position.Location = input.Location
 	position.MinWorkExp = input.MinWorkExp
 	position.Description = input.Description
@@ -179,6 +229,8 @@ func EditPosition(c *gin.Context) {
 		return
 	}
 
+	refreshEmbedding(models.EmbeddingOwnerPosition, position.ID, positionEmbeddingText(position))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Position updated successfully", "position": position})
 }
 
@@ -219,134 +271,44 @@ func EditPositionCandidates(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Position updated successfully", "position": position})
 }
 
+// ResolvePosition marks a position filled, or reopens a filled one.
 func ResolvePosition(c *gin.Context) {
-	userClaims := c.MustGet("claims").(*utils.Claims)
-	id := c.Param("id")
-	var position models.Position
-	if err := config.DB.First(&position, id).Error; err != nil {
-		log.Printf("Position not found: %v\n", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
-		return
-	}
-
-	var department models.Department
-	if err := config.DB.First(&department, position.DepartmentID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": "Department does not exist"})
-		return
-	}
-
-	if department.CompanyID != userClaims.CompanyID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to resolve this position"})
-		return
-	}
-
-	position.IsResolved = !position.IsResolved
-
-	if err := config.DB.Save(&position).Error; err != nil {
-		log.Printf("Failed to update position resolved status: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update position resolved status"})
-		return
-	}
-
-	statusMessage := "Position resolved successfully"
-	if !position.IsResolved {
-		statusMessage = "Position restored successfully"
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": statusMessage})
+	togglePositionStatus(c, "resolve", models.PositionFilled, "Position resolved successfully", "Position restored successfully")
 }
 
+// TrashPosition closes a position, or reopens a closed one.
 func TrashPosition(c *gin.Context) {
-    userClaims := c.MustGet("claims").(*utils.Claims)
-    id := c.Param("id")
-    var position models.Position
-    if err := config.DB.First(&position, id).Error; err != nil {
-        log.Printf("Position not found: %v\n", err)
-        c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
-        return
-    }
-
-    var department models.Department
-    if err := config.DB.First(&department, position.DepartmentID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Department does not exist"})
-        return
-    }
-
-    if department.CompanyID != userClaims.CompanyID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to trash this position"})
-        return
-    }
-
-    position.IsTrash = !position.IsTrash
-    if position.IsTrash {
-        position.RemovedDate = time.Now()
-    }
-
-    if err := config.DB.Save(&position).Error; err != nil {
-        log.Printf("Failed to trash position: %v\n", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trash position"})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{"message": "Position trashed successfully", "position": position})
+    togglePositionStatus(c, "trash", models.PositionClosed, "Position trashed successfully", "Position restored successfully")
 }
 
-
+// ArchivePosition archives a filled or closed position, or unarchives an
+// archived one.
 func ArchivePosition(c *gin.Context) {
-    userClaims := c.MustGet("claims").(*utils.Claims)
-    id := c.Param("id")
-    var position models.Position
-    if err := config.DB.First(&position, id).Error; err != nil {
-        log.Printf("Position not found: %v\n", err)
-        c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
-        return
-    }
-
-    var department models.Department
-    if err := config.DB.First(&department, position.DepartmentID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Department does not exist"})
-        return
-    }
-
-    if department.CompanyID != userClaims.CompanyID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to archive this position"})
-        return
-    }
-
-    position.IsArchive = !position.IsArchive
-
-    if err := config.DB.Save(&position).Error; err != nil {
-        log.Printf("Failed to archive position: %v\n", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive position"})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{"message": "Position archived successfully", "position": position})
+    togglePositionStatus(c, "archive", models.PositionArchived, "Position archived successfully", "Position unarchived successfully")
 }
 
 func GetArchivedPositions(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
 
-    var departments []models.Department
-    if err := config.DB.Where("company_id = ?", userClaims.CompanyID).Find(&departments).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"message": "Departments do not exist"})
+    params, err := utils.ParseListParams(c, "positions", utils.NameAndCreatedSort("positions"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
         return
     }
 
-    var departmentIDs []uint
-    for _, dept := range departments {
-        departmentIDs = append(departmentIDs, dept.ID)
-    }
-
-    var positions []models.Position
-    if err := config.DB.Where("department_id IN (?) AND is_archive = ?", departmentIDs, true).Find(&positions).Error; err != nil {
+    query := companyPositionsQuery(userClaims.CompanyID).Where("positions.status = ?", models.PositionArchived)
+    page, err := utils.Paginate[models.Position](query, params)
+    if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"message": "Archived positions do not exist"})
         return
     }
 
-    c.JSON(http.StatusOK, positions)
+    c.JSON(http.StatusOK, page)
 }
 
+// DeletePosition moves the positions in the request body to the trash,
+// together with their candidates, and reports the outcome for each ID. If any
+// position is missing or fails, none is deleted.
 func DeletePosition(c *gin.Context) {
 	userClaims := c.MustGet("claims").(*utils.Claims)
 	var input DeletePositionInput
@@ -355,80 +317,19 @@ func DeletePosition(c *gin.Context) {
 		return
 	}
 
-	// Start a database transaction
-	tx := config.DB.Begin()
-	if tx.Error != nil {
-		log.Printf("Failed to start transaction: %v\n", tx.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
-		return
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Printf("Panic occurred: %v\n", r)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete positions"})
-		}
-	}()
-
-	for _, id := range input.IDs {
-		var position models.Position
-		if err := tx.First(&position, id).Error; err != nil {
-			tx.Rollback()
-			log.Printf("Position not found: %v\n", err)
-			c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
-			return
-		}
-
-		var department models.Department
-		if err := config.DB.First(&department, position.DepartmentID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"message": "Department does not exist"})
-			return
-		}
-
-		if department.CompanyID != userClaims.CompanyID {
-			tx.Rollback()
-			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to delete this position"})
-			return
-		}
-
-		var candidates []models.Candidate
-		if err := tx.Where("position_id = ?", position.ID).Find(&candidates).Error; err != nil {
-			tx.Rollback()
-			log.Printf("Failed to retrieve candidates: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve candidates"})
-			return
-		}
-
-		for _, candidate := range candidates {
-			if err := utils.DeleteFileFromFirebase(candidate.CVFile); err != nil {
-				tx.Rollback()
-				log.Printf("Failed to delete file from S3: %v\n", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file from S3"})
-				return
-			}
-
-			if err := tx.Delete(&candidate).Error; err != nil {
-				tx.Rollback()
-				log.Printf("Failed to delete candidate: %v\n", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete candidate"})
-				return
-			}
-		}
-
-		if err := tx.Delete(&position).Error; err != nil {
-			tx.Rollback()
-			log.Printf("Failed to delete position: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete position"})
-			return
+	deletedAt := time.Now()
+	runBulk(c, input.IDs, companyPositionsLoader(userClaims.CompanyID), func(tx *gorm.DB, position *models.Position) (string, error) {
+		if err := trashPositions(tx, []uint{position.ID}, deletedAt); err != nil {
+			return "", err
 		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		log.Printf("Failed to commit transaction: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
-	}
+		return BulkDeleted, nil
+	})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Positions deleted successfully"})
+// companyPositionsQuery selects the positions of every department in the
+// company.
+func companyPositionsQuery(companyID uint) *gorm.DB {
+	return config.DB.Model(&models.Position{}).
+		Joins("JOIN departments ON positions.department_id = departments.id").
+		Where("departments.company_id = ?", companyID)
 }
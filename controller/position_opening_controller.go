@@ -0,0 +1,317 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "errors"
+    "log"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// positionCloseInterval is how often positions past their application
+// deadline are closed.
+const positionCloseInterval = time.Hour
+
+// PositionOpeningInput describes how many people a position hires, by when
+// and on what terms. A missing headcount means one.
+type PositionOpeningInput struct {
+    Headcount           int        `json:"headcount" binding:"omitempty,min=1"`
+    ApplicationDeadline *time.Time `json:"applicationDeadline"`
+    SalaryMin           *int64     `json:"salaryMin" binding:"omitempty,min=0"`
+    SalaryMax           *int64     `json:"salaryMax" binding:"omitempty,min=0"`
+    SalaryCurrency      string     `json:"salaryCurrency" binding:"omitempty,len=3,alpha"`
+    EmploymentType      string     `json:"employmentType" binding:"omitempty,oneof=full_time part_time contract internship temporary"`
+}
+
+// HiringTeamInput names the company users who hire for a position.
+type HiringTeamInput struct {
+    RecruiterID     *uint  `json:"recruiterId"`
+    HiringManagerID *uint  `json:"hiringManagerId"`
+    InterviewerIDs  []uint `json:"interviewerIds"`
+}
+
+// HiringTeamMember is a member of a position's hiring team as returned by
+// the API.
+type HiringTeamMember struct {
+    UserID uint   `json:"userId"`
+    Name   string `json:"name"`
+    Email  string `json:"email"`
+    Role   string `json:"role"`
+}
+
+// errSalaryRange rejects a salary range whose minimum exceeds its maximum.
+var errSalaryRange = errors.New("salaryMin must not exceed salaryMax")
+
+// apply copies the opening details onto the position.
+func (input PositionOpeningInput) apply(position *models.Position) error {
+    if input.SalaryMin != nil && input.SalaryMax != nil && *input.SalaryMin > *input.SalaryMax {
+        return errSalaryRange
+    }
+
+    position.Headcount = input.Headcount
+    if position.Headcount == 0 {
+        position.Headcount = 1
+    }
+    position.ApplicationDeadline = input.ApplicationDeadline
+    position.SalaryMin = input.SalaryMin
+    position.SalaryMax = input.SalaryMax
+    position.SalaryCurrency = strings.ToUpper(input.SalaryCurrency)
+    position.EmploymentType = input.EmploymentType
+    return nil
+}
+
+// members lists the team as position team members.
+func (input HiringTeamInput) members(positionID uint) []models.PositionTeamMember {
+    var members []models.PositionTeamMember
+    if input.RecruiterID != nil {
+        members = append(members, models.PositionTeamMember{PositionID: positionID, UserID: *input.RecruiterID, Role: models.TeamRecruiter})
+    }
+    if input.HiringManagerID != nil {
+        members = append(members, models.PositionTeamMember{PositionID: positionID, UserID: *input.HiringManagerID, Role: models.TeamHiringManager})
+    }
+    for _, id := range uniqueIDs(input.InterviewerIDs) {
+        members = append(members, models.PositionTeamMember{PositionID: positionID, UserID: id, Role: models.TeamInterviewer})
+    }
+    return members
+}
+
+// checkCompanyUsers reports whether every user on the team belongs to the
+// company.
+func (input HiringTeamInput) checkCompanyUsers(db *gorm.DB, companyID uint) (bool, error) {
+    var ids []uint
+    for _, member := range input.members(0) {
+        ids = append(ids, member.UserID)
+    }
+    ids = uniqueIDs(ids)
+    if len(ids) == 0 {
+        return true, nil
+    }
+
+    var count int64
+    if err := db.Model(&models.User{}).Where("id IN ? AND company_id = ?", ids, companyID).Count(&count).Error; err != nil {
+        return false, err
+    }
+    return count == int64(len(ids)), nil
+}
+
+// replaceHiringTeam makes the team the position's whole hiring team.
+func replaceHiringTeam(tx *gorm.DB, positionID uint, team HiringTeamInput) error {
+    if err := tx.Where("position_id = ?", positionID).Delete(&models.PositionTeamMember{}).Error; err != nil {
+        return err
+    }
+    members := team.members(positionID)
+    if len(members) == 0 {
+        return nil
+    }
+    return tx.Create(&members).Error
+}
+
+// EditPositionOpening replaces the headcount, application deadline, salary
+// range and employment type of a position. A position that already hired
+// its new headcount is filled, and one whose new deadline has passed is
+// closed.
+func EditPositionOpening(c *gin.Context) {
+    var input PositionOpeningInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    position, ok := loadCompanyPosition(c, c.Param("id"), "edit")
+    if !ok {
+        return
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        locked, err := lockPosition(tx, position.ID)
+        if err != nil {
+            return err
+        }
+        if err := input.apply(&locked); err != nil {
+            return err
+        }
+        if err := tx.Model(&locked).Select("headcount", "application_deadline", "salary_min", "salary_max", "salary_currency", "employment_type").Updates(&locked).Error; err != nil {
+            return err
+        }
+        if err := autoClosePosition(tx, &locked, time.Now()); err != nil {
+            return err
+        }
+        locked.Department = position.Department
+        position = locked
+        return nil
+    })
+    if err == errSalaryRange {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update position opening", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Position opening updated successfully", "position": position})
+}
+
+// EditHiringTeam replaces the hiring team of a position.
+func EditHiringTeam(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input HiringTeamInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    position, ok := loadCompanyPosition(c, c.Param("id"), "edit")
+    if !ok {
+        return
+    }
+
+    valid, err := input.checkCompanyUsers(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to check users", "error": err.Error()})
+        return
+    }
+    if !valid {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Hiring team members must be users of your company"})
+        return
+    }
+
+    if err := config.DB.Transaction(func(tx *gorm.DB) error {
+        return replaceHiringTeam(tx, position.ID, input)
+    }); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update hiring team", "error": err.Error()})
+        return
+    }
+
+    team, err := loadHiringTeam(config.DB, position.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve hiring team", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Hiring team updated successfully", "positionId": position.ID, "team": team})
+}
+
+// GetHiringTeam lists the hiring team of a position.
+func GetHiringTeam(c *gin.Context) {
+    position, ok := loadCompanyPosition(c, c.Param("id"), "view")
+    if !ok {
+        return
+    }
+
+    team, err := loadHiringTeam(config.DB, position.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve hiring team", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"positionId": position.ID, "team": team})
+}
+
+// loadHiringTeam returns the team of a position with each member's name and
+// email, recruiter first and then hiring manager and interviewers.
+func loadHiringTeam(db *gorm.DB, positionID uint) ([]HiringTeamMember, error) {
+    team := []HiringTeamMember{}
+    err := db.Model(&models.PositionTeamMember{}).
+        Select("position_team_members.user_id, users.name, users.email, position_team_members.role").
+        Joins("JOIN users ON users.id = position_team_members.user_id").
+        Where("position_team_members.position_id = ?", positionID).
+        Order(clause.Expr{
+            SQL:  "CASE position_team_members.role WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END, users.name",
+            Vars: []interface{}{models.TeamRecruiter, models.TeamHiringManager},
+        }).
+        Scan(&team).Error
+    return team, err
+}
+
+// lockPosition loads a position and locks it until the transaction ends.
+func lockPosition(tx *gorm.DB, id uint) (models.Position, error) {
+    var position models.Position
+    err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&position, id).Error
+    return position, err
+}
+
+// autoClosePosition fills an open or on-hold position that has hired its
+// headcount, and closes one whose application deadline has passed. The
+// position must be locked.
+func autoClosePosition(tx *gorm.DB, position *models.Position, now time.Time) error {
+    if position.Status != models.PositionOpen && position.Status != models.PositionOnHold {
+        return nil
+    }
+
+    var hired int64
+    if err := tx.Model(&models.Candidate{}).
+        Where("position_id = ? AND stage = ?", position.ID, models.StageHired).
+        Count(&hired).Error; err != nil {
+        return err
+    }
+    if hired >= int64(position.Headcount) {
+        return changePositionStatus(tx, position, models.PositionFilled, nil)
+    }
+
+    if position.ApplicationDeadline != nil && !now.Before(*position.ApplicationDeadline) {
+        return changePositionStatus(tx, position, models.PositionClosed, nil)
+    }
+    return nil
+}
+
+// RunPositionCloser closes positions past their application deadline every
+// hour until the process exits. Positions fill as soon as they hire their
+// headcount, so only deadlines need checking here.
+func RunPositionCloser() {
+    for {
+        closed, err := CloseExpiredPositions(time.Now())
+        if err != nil {
+            log.Printf("Failed to close expired positions: %v", err)
+        } else if closed > 0 {
+            log.Printf("Closed %d positions past their application deadline", closed)
+        }
+        time.Sleep(positionCloseInterval)
+    }
+}
+
+// CloseExpiredPositions closes the open and on-hold positions whose
+// application deadline passed before now and returns how many changed
+// status.
+func CloseExpiredPositions(now time.Time) (int, error) {
+    var ids []uint
+    if err := config.DB.Model(&models.Position{}).
+        Where("status IN ? AND application_deadline <= ?", []string{models.PositionOpen, models.PositionOnHold}, now).
+        Pluck("id", &ids).Error; err != nil {
+        return 0, err
+    }
+
+    closed := 0
+    for _, id := range ids {
+        changed := false
+        err := config.DB.Transaction(func(tx *gorm.DB) error {
+            position, err := lockPosition(tx, id)
+            if err != nil {
+                return err
+            }
+            status := position.Status
+            if err := autoClosePosition(tx, &position, now); err != nil {
+                return err
+            }
+            changed = position.Status != status
+            return nil
+        })
+        if err == gorm.ErrRecordNotFound {
+            continue
+        }
+        if err != nil {
+            return closed, err
+        }
+        if changed {
+            closed++
+        }
+    }
+    return closed, nil
+}
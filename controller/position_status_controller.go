@@ -0,0 +1,197 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+type ChangePositionStatusInput struct {
+    Status string `json:"status" binding:"required"`
+}
+
+// errInvalidPositionTransition is returned for a status change the position
+// lifecycle does not allow.
+var errInvalidPositionTransition = errors.New("invalid position status transition")
+
+// ChangePositionStatus moves a position through its lifecycle.
+func ChangePositionStatus(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input ChangePositionStatusInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    if !models.IsValidPositionStatus(input.Status) {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown status", "statuses": models.PositionStatuses})
+        return
+    }
+
+    position, ok := loadCompanyPosition(c, c.Param("id"), "change")
+    if !ok {
+        return
+    }
+
+    from := position.Status
+    unchanged := false
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        locked, err := lockPosition(tx, position.ID)
+        if err != nil {
+            return err
+        }
+        locked.Department = position.Department
+        position, from = locked, locked.Status
+        if position.Status == input.Status {
+            unchanged = true
+            return nil
+        }
+        return changePositionStatus(tx, &position, input.Status, &userClaims.UserID)
+    })
+    if unchanged {
+        c.JSON(http.StatusOK, gin.H{"message": "Position is already in this status", "position": position})
+        return
+    }
+    if errors.Is(err, errInvalidPositionTransition) {
+        c.JSON(http.StatusConflict, gin.H{"message": err.Error(), "allowed": models.PositionTransitions(from)})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to change position status", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Position status changed successfully", "position": position})
+}
+
+// GetPositionStatusHistory lists the status changes of a position, oldest
+// first.
+func GetPositionStatusHistory(c *gin.Context) {
+    position, ok := loadCompanyPosition(c, c.Param("id"), "view")
+    if !ok {
+        return
+    }
+
+    var history []models.PositionStatusHistory
+    if err := config.DB.Where("position_id = ?", position.ID).Order("changed_date, id").Find(&history).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve status history", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"positionId": position.ID, "status": position.Status, "history": history})
+}
+
+// togglePositionStatus backs the resolve, trash and archive endpoints, which
+// switch a position into status or, when it is already there, back out of
+// it: reopened, or returned to where it was archived from.
+func togglePositionStatus(c *gin.Context, action, status, message, undoMessage string) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    position, ok := loadCompanyPosition(c, c.Param("id"), action)
+    if !ok {
+        return
+    }
+
+    from := position.Status
+    target := status
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        locked, err := lockPosition(tx, position.ID)
+        if err != nil {
+            return err
+        }
+        locked.Department = position.Department
+        position, from = locked, locked.Status
+        if position.Status == status {
+            target = models.PositionOpen
+            if status == models.PositionArchived {
+                if target, err = unarchivedStatus(tx, position.ID); err != nil {
+                    return err
+                }
+            }
+        }
+        return changePositionStatus(tx, &position, target, &userClaims.UserID)
+    })
+    if errors.Is(err, errInvalidPositionTransition) {
+        c.JSON(http.StatusConflict, gin.H{"message": err.Error(), "allowed": models.PositionTransitions(from)})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to change position status", "error": err.Error()})
+        return
+    }
+
+    if target != status {
+        message = undoMessage
+    }
+    c.JSON(http.StatusOK, gin.H{"message": message, "position": position})
+}
+
+// changePositionStatus moves the position to status and appends the change
+// to its status history, failing with errInvalidPositionTransition when the
+// lifecycle does not allow the move. Automatic changes have no user. The
+// position must be locked, so that concurrent changes are checked against
+// the status each of them actually moves from.
+func changePositionStatus(tx *gorm.DB, position *models.Position, status string, userID *uint) error {
+    if !models.CanTransitionPosition(position.Status, status) {
+        return fmt.Errorf("%w from %s to %s", errInvalidPositionTransition, position.Status, status)
+    }
+
+    now := time.Now()
+    history := models.PositionStatusHistory{
+        PositionID:  position.ID,
+        FromStatus:  position.Status,
+        ToStatus:    status,
+        UserID:      userID,
+        ChangedDate: now,
+    }
+    if err := tx.Model(position).Updates(map[string]interface{}{"status": status, "status_date": now}).Error; err != nil {
+        return err
+    }
+    position.Status, position.StatusDate = status, now
+    return tx.Create(&history).Error
+}
+
+// unarchivedStatus returns the status an archived position was archived
+// from, or closed when that is unknown.
+func unarchivedStatus(tx *gorm.DB, positionID uint) (string, error) {
+    var history models.PositionStatusHistory
+    err := tx.Where("position_id = ? AND to_status = ?", positionID, models.PositionArchived).
+        Order("changed_date DESC, id DESC").
+        First(&history).Error
+    if err == gorm.ErrRecordNotFound {
+        return models.PositionClosed, nil
+    }
+    if err != nil {
+        return "", err
+    }
+    if !models.CanTransitionPosition(models.PositionArchived, history.FromStatus) {
+        return models.PositionClosed, nil
+    }
+    return history.FromStatus, nil
+}
+
+// loadCompanyPosition loads a position and checks that it belongs to the
+// caller's company. When it does not, the error response has already been
+// written and ok is false.
+func loadCompanyPosition(c *gin.Context, id interface{}, action string) (models.Position, bool) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var position models.Position
+    if err := config.DB.Preload("Department").First(&position, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+        return position, false
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to " + action + " this position"})
+        return position, false
+    }
+
+    return position, true
+}
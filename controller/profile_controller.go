@@ -0,0 +1,366 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+    "sort"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// nameMatchThreshold is the minimum name similarity for two profiles to be
+// reported as possible duplicates when neither email nor phone match.
+const nameMatchThreshold = 0.85
+
+type MergeProfilesInput struct {
+    TargetID  uint   `json:"targetId" binding:"required"`
+    SourceIDs []uint `json:"sourceIds" binding:"required,min=1"`
+}
+
+type ProfileMatch struct {
+    Profile models.Profile `json:"profile"`
+    Score   float64        `json:"score"`
+    Reasons []string       `json:"reasons"`
+}
+
+type DuplicateProfilePair struct {
+    ProfileID   uint     `json:"profileId"`
+    DuplicateID uint     `json:"duplicateId"`
+    Score       float64  `json:"score"`
+    Reasons     []string `json:"reasons"`
+}
+
+func GetAllProfiles(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "profiles", utils.NameAndCreatedSort("profiles"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.Profile{}).Where("company_id = ?", userClaims.CompanyID)
+    page, err := utils.Paginate[models.Profile](query, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve profiles", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func GetOneProfile(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var profile models.Profile
+    if err := config.DB.Preload("Candidates.Position").Preload("Candidates.Notes").First(&profile, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Profile does not exist"})
+        return
+    }
+
+    if profile.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this profile"})
+        return
+    }
+
+    c.JSON(http.StatusOK, profile)
+}
+
+// GetProfileDuplicates lists the company's profiles that look like the same
+// person as the given profile.
+func GetProfileDuplicates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var profile models.Profile
+    if err := config.DB.First(&profile, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Profile does not exist"})
+        return
+    }
+
+    if profile.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this profile"})
+        return
+    }
+
+    matches, err := findProfileMatches(config.DB, profile.CompanyID, profile.Name, profile.Email, profile.Phone)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to search for duplicates", "error": err.Error()})
+        return
+    }
+
+    var duplicates []ProfileMatch
+    for _, match := range matches {
+        if match.Profile.ID != profile.ID {
+            duplicates = append(duplicates, match)
+        }
+    }
+
+    c.JSON(http.StatusOK, duplicates)
+}
+
+// GetDuplicateProfiles returns the pairs of the company's profiles that are
+// likely to be the same person. Only pairs sharing an email, a phone or a
+// name word are compared.
+func GetDuplicateProfiles(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var candidatePairs []struct {
+        ProfileID   uint
+        DuplicateID uint
+    }
+    if err := config.DB.Table("profiles AS a").
+        Select("a.id AS profile_id, b.id AS duplicate_id").
+        Joins(`JOIN profiles AS b ON b.company_id = a.company_id AND b.id > a.id AND (
+            (a.email <> '' AND b.email = a.email) OR
+            (a.phone <> '' AND b.phone = a.phone) OR
+            string_to_array(b.normalized_name, ' ') && string_to_array(a.normalized_name, ' '))`).
+        Where("a.company_id = ?", userClaims.CompanyID).
+        Order("a.id, b.id").
+        Scan(&candidatePairs).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve profiles", "error": err.Error()})
+        return
+    }
+
+    var ids []uint
+    for _, pair := range candidatePairs {
+        ids = append(ids, pair.ProfileID, pair.DuplicateID)
+    }
+    profiles := make(map[uint]models.Profile)
+    if len(ids) > 0 {
+        var loaded []models.Profile
+        if err := config.DB.Where("id IN ?", uniqueIDs(ids)).Find(&loaded).Error; err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve profiles", "error": err.Error()})
+            return
+        }
+        for _, profile := range loaded {
+            profiles[profile.ID] = profile
+        }
+    }
+
+    var pairs []DuplicateProfilePair
+    for _, pair := range candidatePairs {
+        profile, duplicate := profiles[pair.ProfileID], profiles[pair.DuplicateID]
+        score, reasons := compareProfile(duplicate, profile.NormalizedName, profile.Email, profile.Phone)
+        if score > 0 {
+            pairs = append(pairs, DuplicateProfilePair{
+                ProfileID:   pair.ProfileID,
+                DuplicateID: pair.DuplicateID,
+                Score:       score,
+                Reasons:     reasons,
+            })
+        }
+    }
+
+    sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].Score > pairs[b].Score })
+
+    c.JSON(http.StatusOK, pairs)
+}
+
+// MergeProfiles moves every application of the source profiles, together with
+// their notes and CV files, onto the target profile and removes the sources.
+func MergeProfiles(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input MergeProfilesInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var sourceIDs []uint
+    for _, id := range uniqueIDs(input.SourceIDs) {
+        if id != input.TargetID {
+            sourceIDs = append(sourceIDs, id)
+        }
+    }
+    if len(sourceIDs) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "At least one profile other than the target must be merged"})
+        return
+    }
+
+    var target models.Profile
+    if err := config.DB.First(&target, input.TargetID).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Profile does not exist"})
+        return
+    }
+
+    if target.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to merge this profile"})
+        return
+    }
+
+    var sources []models.Profile
+    if err := config.DB.Where("id IN ? AND company_id = ?", sourceIDs, userClaims.CompanyID).Find(&sources).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve profiles", "error": err.Error()})
+        return
+    }
+    if len(sources) != len(sourceIDs) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to one or more of these profiles"})
+        return
+    }
+
+    for _, source := range sources {
+        if target.Email == "" {
+            target.Email = source.Email
+        }
+        if target.Phone == "" {
+            target.Phone = source.Phone
+        }
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        // Trashed candidates move too, so they keep a profile if restored.
+        if err := tx.Unscoped().Model(&models.Candidate{}).Where("profile_id IN ?", sourceIDs).Update("profile_id", target.ID).Error; err != nil {
+            return err
+        }
+        if err := tx.Save(&target).Error; err != nil {
+            return err
+        }
+        return tx.Delete(&models.Profile{}, sourceIDs).Error
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to merge profiles", "error": err.Error()})
+        return
+    }
+
+    if err := config.DB.Preload("Candidates.Position").Preload("Candidates.Notes").First(&target, target.ID).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load profile", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Profiles merged successfully", "profile": target})
+}
+
+// resolveProfile returns the company profile an application belongs to. An
+// exact email or phone match links to the existing profile; otherwise a new
+// profile is created and any fuzzy name matches are returned so the caller can
+// surface them for review.
+func resolveProfile(tx *gorm.DB, companyID uint, name, email, phone string) (models.Profile, []ProfileMatch, error) {
+    matches, err := findProfileMatches(tx, companyID, name, email, phone)
+    if err != nil {
+        return models.Profile{}, nil, err
+    }
+
+    for _, match := range matches {
+        if containsReason(match.Reasons, "email") || containsReason(match.Reasons, "phone") {
+            profile := match.Profile
+            if profile.Phone == "" && phone != "" {
+                profile.Phone = utils.NormalizePhone(phone)
+                if err := tx.Save(&profile).Error; err != nil {
+                    return models.Profile{}, nil, err
+                }
+            }
+            return profile, nil, nil
+        }
+    }
+
+    profile := models.Profile{
+        Name:           name,
+        NormalizedName: utils.NormalizeName(name),
+        Email:          utils.NormalizeEmail(email),
+        Phone:          utils.NormalizePhone(phone),
+        CompanyID:      companyID,
+    }
+    if err := tx.Create(&profile).Error; err != nil {
+        return models.Profile{}, nil, err
+    }
+
+    return profile, matches, nil
+}
+
+// deleteEmptyProfile deletes a profile no candidate belongs to any more,
+// trashed ones included.
+func deleteEmptyProfile(tx *gorm.DB, id uint) error {
+    var count int64
+    if err := tx.Unscoped().Model(&models.Candidate{}).Where("profile_id = ?", id).Count(&count).Error; err != nil {
+        return err
+    }
+    if count > 0 {
+        return nil
+    }
+    return tx.Delete(&models.Profile{}, id).Error
+}
+
+// findProfileMatches returns the company's profiles that match the given
+// identity on email, phone or a similar normalized name, best match first.
+// Similar names are only looked for among profiles sharing a name word, so
+// the lookup stays on indexes.
+func findProfileMatches(db *gorm.DB, companyID uint, name, email, phone string) ([]ProfileMatch, error) {
+    normalizedName := utils.NormalizeName(name)
+    normalizedEmail := utils.NormalizeEmail(email)
+    normalizedPhone := utils.NormalizePhone(phone)
+
+    var conditions []string
+    var args []interface{}
+    if normalizedEmail != "" {
+        conditions = append(conditions, "email = ?")
+        args = append(args, normalizedEmail)
+    }
+    if normalizedPhone != "" {
+        conditions = append(conditions, "phone = ?")
+        args = append(args, normalizedPhone)
+    }
+    if normalizedName != "" {
+        conditions = append(conditions, "string_to_array(normalized_name, ' ') && string_to_array(?, ' ')")
+        args = append(args, normalizedName)
+    }
+    if len(conditions) == 0 {
+        return nil, nil
+    }
+
+    var profiles []models.Profile
+    if err := db.Where("company_id = ?", companyID).
+        Where(strings.Join(conditions, " OR "), args...).
+        Find(&profiles).Error; err != nil {
+        return nil, err
+    }
+
+    var matches []ProfileMatch
+    for _, profile := range profiles {
+        score, reasons := compareProfile(profile, normalizedName, normalizedEmail, normalizedPhone)
+        if score > 0 {
+            matches = append(matches, ProfileMatch{Profile: profile, Score: score, Reasons: reasons})
+        }
+    }
+
+    sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+    return matches, nil
+}
+
+// compareProfile scores how likely a profile is the same person as the given
+// normalized identity. Email is the strongest signal, then phone, then name.
+func compareProfile(profile models.Profile, normalizedName, normalizedEmail, normalizedPhone string) (float64, []string) {
+    var score float64
+    var reasons []string
+
+    if normalizedEmail != "" && profile.Email == normalizedEmail {
+        score = 1
+        reasons = append(reasons, "email")
+    }
+
+    if normalizedPhone != "" && profile.Phone == normalizedPhone {
+        score = max(score, 0.9)
+        reasons = append(reasons, "phone")
+    }
+
+    if similarity := utils.NameSimilarity(profile.NormalizedName, normalizedName); similarity >= nameMatchThreshold {
+        score = max(score, 0.8*similarity)
+        reasons = append(reasons, "name")
+    }
+
+    return score, reasons
+}
+
+func containsReason(reasons []string, reason string) bool {
+    for _, r := range reasons {
+        if r == reason {
+            return true
+        }
+    }
+    return false
+}
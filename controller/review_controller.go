@@ -0,0 +1,225 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/extractor"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// ReviewCandidateInput holds the corrected values of extracted fields, keyed
+// by field name. Fields left out are accepted as extracted.
+type ReviewCandidateInput struct {
+    Corrections map[string]json.RawMessage `json:"corrections"`
+}
+
+type FieldAccuracy struct {
+    ExtractionVersion int     `json:"extractionVersion"`
+    Field             string  `json:"field"`
+    Reviewed          int     `json:"reviewed"`
+    Corrected         int     `json:"corrected"`
+    Accuracy          float64 `json:"accuracy"`
+    MeanConfidence    float64 `json:"meanConfidence"`
+}
+
+// GetReviewQueue lists the company's candidates whose extracted data awaits
+// review. sort=confidence puts the least confident extractions first.
+func GetReviewQueue(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    sortFields := candidateSortFields()
+    sortFields["confidence"] = utils.SortField{Column: "candidates.extraction_confidence", Field: "ExtractionConfidence", Kind: utils.SortNumber}
+
+    params, err := utils.ParseListParams(c, "candidates", sortFields)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := companyCandidatesQuery(userClaims.CompanyID).Where("candidates.review_status = ?", models.ReviewPending)
+    page, err := utils.Paginate[models.Candidate](query, params, "Position", "FieldExtractions")
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve candidates", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+// ReviewCandidate accepts or corrects each extracted field of a candidate.
+// The verdicts are stored apart from the raw extraction, which stays as the
+// parser produced it.
+func ReviewCandidate(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input ReviewCandidateInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "review")
+    if !ok {
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    for field, value := range input.Corrections {
+        if err := applyFieldCorrection(&candidate, field, value, taxonomy); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid correction", "details": err.Error()})
+            return
+        }
+    }
+
+    var extractions []models.CandidateFieldExtraction
+    if err := config.DB.Where("candidate_id = ?", candidate.ID).Find(&extractions).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve extracted fields", "error": err.Error()})
+        return
+    }
+    extracted := make(map[string]models.CandidateFieldExtraction, len(extractions))
+    for _, extraction := range extractions {
+        extracted[extraction.Field] = extraction
+    }
+
+    candidate.ReviewStatus = models.ReviewReviewed
+    err = config.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&candidate).
+            Select("language", "cv_text_config", "skills", "education_level", "experience_months", "review_status").
+            Updates(&candidate).Error; err != nil {
+            return err
+        }
+
+        // Only the latest review of a field counts towards accuracy.
+        if err := tx.Where("candidate_id = ? AND cv_version = ?", candidate.ID, candidate.CVVersion).Delete(&models.CandidateFieldReview{}).Error; err != nil {
+            return err
+        }
+        for _, field := range models.ExtractedFields {
+            reviewed := candidate.FieldValue(field)
+            if err := tx.Create(&models.CandidateFieldReview{
+                CandidateID:         candidate.ID,
+                Field:               field,
+                ExtractedValue:      extracted[field].Value,
+                ReviewedValue:       reviewed,
+                Corrected:           reviewed != extracted[field].Value,
+                CVVersion:           candidate.CVVersion,
+                ExtractionVersion:   candidate.ExtractionVersion,
+                ExtractedConfidence: extracted[field].Confidence,
+                UserID:              &userClaims.UserID,
+            }).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to save review", "error": err.Error()})
+        return
+    }
+
+    if _, ok := input.Corrections[models.FieldSkills]; ok {
+        refreshEmbedding(models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate))
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Candidate reviewed successfully", "candidate": candidate})
+}
+
+// applyFieldCorrection sets an extracted field of the candidate from its
+// corrected JSON value.
+func applyFieldCorrection(candidate *models.Candidate, field string, value json.RawMessage, taxonomy skillTaxonomy) error {
+    switch field {
+    case models.FieldLanguage:
+        var language string
+        if err := json.Unmarshal(value, &language); err != nil {
+            return fmt.Errorf("%s must be a string", field)
+        }
+        if !extractor.IsSupportedLanguage(language) {
+            return fmt.Errorf("unsupported language %q", language)
+        }
+        candidate.Language = language
+        candidate.CVTextConfig = extractor.SearchConfig(language)
+    case models.FieldSkills:
+        var skills string
+        if err := json.Unmarshal(value, &skills); err != nil {
+            return fmt.Errorf("%s must be a string", field)
+        }
+        candidate.Skills = strings.Join(taxonomy.Normalize(skills), ", ")
+    case models.FieldEducationLevel:
+        var level models.EducationLevel
+        if err := json.Unmarshal(value, &level); err != nil {
+            return err
+        }
+        candidate.EducationLevel = level
+    case models.FieldExperienceMonths:
+        var months int
+        if err := json.Unmarshal(value, &months); err != nil || months < 0 {
+            return fmt.Errorf("%s must be a non-negative integer", field)
+        }
+        candidate.ExperienceMonths = months
+    default:
+        return fmt.Errorf("unknown field %q", field)
+    }
+    return nil
+}
+
+// GetExtractionAccuracy reports, for each extraction version and field, how
+// often recruiters accepted the parser's value among the company's reviewed
+// candidates, with the confidence the parser gave the reviewed values. The
+// optional from and to dates narrow the report to reviews made in that range.
+func GetExtractionAccuracy(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    query := config.DB.Table("candidate_field_reviews").
+        Select("candidate_field_reviews.extraction_version, candidate_field_reviews.field, COUNT(*) AS reviewed, "+
+            "SUM(CASE WHEN candidate_field_reviews.corrected THEN 1 ELSE 0 END) AS corrected, "+
+            "AVG(candidate_field_reviews.extracted_confidence) AS mean_confidence").
+        Joins("JOIN candidates ON candidate_field_reviews.candidate_id = candidates.id").
+        Joins("JOIN positions ON candidates.position_id = positions.id").
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("departments.company_id = ?", userClaims.CompanyID)
+
+    if value := c.Query("from"); value != "" {
+        from, err := time.Parse(analyticsDateLayout, value)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid from date", "details": err.Error()})
+            return
+        }
+        query = query.Where("candidate_field_reviews.reviewed_date >= ?", from)
+    }
+    if value := c.Query("to"); value != "" {
+        to, err := time.Parse(analyticsDateLayout, value)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid to date", "details": err.Error()})
+            return
+        }
+        query = query.Where("candidate_field_reviews.reviewed_date < ?", to.AddDate(0, 0, 1))
+    }
+
+    var accuracies []FieldAccuracy
+    if err := query.
+        Group("candidate_field_reviews.extraction_version, candidate_field_reviews.field").
+        Order("candidate_field_reviews.extraction_version, candidate_field_reviews.field").
+        Scan(&accuracies).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute accuracy", "error": err.Error()})
+        return
+    }
+
+    for i := range accuracies {
+        if accuracies[i].Reviewed > 0 {
+            accuracies[i].Accuracy = float64(accuracies[i].Reviewed-accuracies[i].Corrected) / float64(accuracies[i].Reviewed)
+        }
+    }
+
+    c.JSON(http.StatusOK, accuracies)
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/scoring"
+    "cv-extractor/utils"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+type CandidateScoreResult struct {
+    CandidateID uint `json:"candidateId"`
+    scoring.Result
+}
+
+// RescoreCandidates scores every candidate of a position against the
+// position's requirements and stores the new scores.
+func RescoreCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    positionID := c.Param("positionId")
+
+    var position models.Position
+    if err := config.DB.Preload("Department").First(&position, positionID).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
+        return
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to score candidates for this position"})
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    var candidates []models.Candidate
+    if err := config.DB.Where("position_id = ?", position.ID).Find(&candidates).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve candidates", "error": err.Error()})
+        return
+    }
+
+    results := make([]CandidateScoreResult, 0, len(candidates))
+    err = config.DB.Transaction(func(tx *gorm.DB) error {
+        for _, candidate := range candidates {
+            result := scoreCandidate(candidate, position, taxonomy)
+            candidate.SetScore(result.Score, time.Now())
+            if err := tx.Model(&candidate).Select("score", "scored_date").Updates(&candidate).Error; err != nil {
+                return err
+            }
+            results = append(results, CandidateScoreResult{CandidateID: candidate.ID, Result: result})
+        }
+        return nil
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update scores", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Candidates rescored successfully", "results": results})
+}
+
+// scoreCandidate rates a candidate against a position. The position's skill
+// requirements are the taxonomy skills its qualification and description
+// mention.
+func scoreCandidate(candidate models.Candidate, position models.Position, taxonomy skillTaxonomy) scoring.Result {
+    requirements := scoring.Requirements{
+        MinExperienceMonths: position.MinWorkExp * 12,
+        MinEducation:        position.EducationLevel,
+        Skills:              taxonomy.Find(position.Qualification + "\n" + position.Description),
+    }
+    facts := scoring.Facts{
+        ExperienceMonths: candidate.ExperienceMonths,
+        Education:        candidate.EducationLevel,
+        Skills:           taxonomy.Normalize(candidate.Skills),
+    }
+    return scoring.Score(requirements, facts)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// searchConfigs are the PostgreSQL text search configurations CV text may be
+// indexed with. A query is parsed with each of them so that it matches CVs
+// regardless of the stemming their text was indexed with. The indonesian
+// configuration requires PostgreSQL 12 or later.
+var searchConfigs = []string{"english", "indonesian", "simple"}
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=3, FragmentDelimiter=\" ... \""
+
+type CVSearchResult struct {
+    Candidate models.Candidate `json:"candidate"`
+    Rank      float64          `json:"rank"`
+    Snippet   string           `json:"snippet"`
+}
+
+// SearchCVs runs a full-text search over the CVs of the caller's company. The
+// q parameter accepts web search syntax: quoted phrases, "or" and "-" to
+// exclude a term. Results are ranked by relevance and carry a snippet with the
+// matching terms wrapped in <mark> tags.
+func SearchCVs(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    q := strings.TrimSpace(c.Query("q"))
+    if q == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Search query is required"})
+        return
+    }
+
+    limit, offset, err := utils.ParseOffsetParams(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    tsquery, queryArgs := searchTSQuery(q)
+    base := companyCandidatesQuery(userClaims.CompanyID).Where("candidates.cv_tsv @@ ("+tsquery+")", queryArgs...)
+
+    var total int64
+    if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to search CVs", "error": err.Error()})
+        return
+    }
+
+    var hits []struct {
+        ID      uint
+        Rank    float64
+        Snippet string
+    }
+    selectArgs := append(append([]interface{}{}, queryArgs...), queryArgs...)
+    err = base.Session(&gorm.Session{}).
+        Select("candidates.id, ts_rank_cd(candidates.cv_tsv, "+tsquery+") AS rank, "+
+            "ts_headline(coalesce(candidates.cv_text_config, 'simple')::regconfig, candidates.cv_text, "+tsquery+", '"+headlineOptions+"') AS snippet",
+            selectArgs...).
+        Order("rank DESC").Order("candidates.id").
+        Limit(limit).Offset(offset).
+        Scan(&hits).Error
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to search CVs", "error": err.Error()})
+        return
+    }
+
+    ids := make([]uint, len(hits))
+    for i, hit := range hits {
+        ids[i] = hit.ID
+    }
+
+    var candidates []models.Candidate
+    if len(ids) > 0 {
+        if err := config.DB.Preload("Position").Where("id IN ?", ids).Find(&candidates).Error; err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load candidates", "error": err.Error()})
+            return
+        }
+    }
+    byID := make(map[uint]models.Candidate, len(candidates))
+    for _, candidate := range candidates {
+        byID[candidate.ID] = candidate
+    }
+
+    results := make([]CVSearchResult, 0, len(hits))
+    for _, hit := range hits {
+        results = append(results, CVSearchResult{Candidate: byID[hit.ID], Rank: hit.Rank, Snippet: hit.Snippet})
+    }
+
+    c.JSON(http.StatusOK, utils.OffsetPage(results, total, limit, offset))
+}
+
+// searchTSQuery builds a tsquery expression matching q under every search
+// configuration, returning the SQL and its arguments.
+func searchTSQuery(q string) (string, []interface{}) {
+    var parts []string
+    var args []interface{}
+    for _, cfg := range searchConfigs {
+        parts = append(parts, "websearch_to_tsquery(CAST(? AS regconfig), ?)")
+        args = append(args, cfg, q)
+    }
+    return strings.Join(parts, " || "), args
+}
@@ -0,0 +1,397 @@
+package controller
+
+import (
+    "errors"
+    "fmt"
+
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+    "sort"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+type SkillInput struct {
+    Name     string   `json:"name" binding:"required"`
+    Category string   `json:"category"`
+    ParentID *uint    `json:"parentId"`
+    Aliases  []string `json:"aliases"`
+}
+
+type NormalizeSkillsInput struct {
+    Skills string `json:"skills" binding:"required"`
+}
+
+type SkillFrequency struct {
+    Skill    string `json:"skill"`
+    Category string `json:"category"`
+    Known    bool   `json:"known"`
+    Count    int    `json:"count"`
+}
+
+func CreateSkill(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input SkillInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    skill := models.Skill{CompanyID: userClaims.CompanyID}
+    if status, err := applySkillInput(config.DB, &skill, input); err != nil {
+        c.JSON(status, gin.H{"message": err.Error()})
+        return
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Omit("Aliases").Create(&skill).Error; err != nil {
+            return err
+        }
+        return replaceSkillAliases(tx, &skill, input.Aliases)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create skill", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Skill created successfully", "skill": skill})
+}
+
+func GetAllSkills(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "skills", utils.NameAndCreatedSort("skills"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.Skill{}).Where("company_id = ?", userClaims.CompanyID)
+    if category := c.Query("category"); category != "" {
+        query = query.Where("category = ?", category)
+    }
+
+    page, err := utils.Paginate[models.Skill](query, params, "Aliases")
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve skills", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func GetOneSkill(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var skill models.Skill
+    if err := config.DB.Preload("Aliases").Preload("Parent").Preload("Children").First(&skill, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Skill does not exist"})
+        return
+    }
+
+    if skill.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this skill"})
+        return
+    }
+
+    c.JSON(http.StatusOK, skill)
+}
+
+func EditSkill(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+    var input SkillInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var skill models.Skill
+    if err := config.DB.First(&skill, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Skill does not exist"})
+        return
+    }
+
+    if skill.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to edit this skill"})
+        return
+    }
+
+    if status, err := applySkillInput(config.DB, &skill, input); err != nil {
+        c.JSON(status, gin.H{"message": err.Error()})
+        return
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Omit("Aliases", "Parent", "Children").Save(&skill).Error; err != nil {
+            return err
+        }
+        return replaceSkillAliases(tx, &skill, input.Aliases)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update skill", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Skill updated successfully", "skill": skill})
+}
+
+// DeleteSkill removes a skill and its aliases. Child skills are kept and
+// become top-level skills.
+func DeleteSkill(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var skill models.Skill
+    if err := config.DB.First(&skill, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Skill does not exist"})
+        return
+    }
+
+    if skill.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to delete this skill"})
+        return
+    }
+
+    if err := config.DB.Delete(&skill).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete skill", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Skill deleted successfully"})
+}
+
+// NormalizeSkills previews how a free-text skill list is normalized against
+// the company's taxonomy.
+func NormalizeSkills(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input NormalizeSkillsInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    skills := taxonomy.Normalize(input.Skills)
+    var unknown []string
+    for _, skill := range skills {
+        if _, ok := taxonomy.Lookup(skill); !ok {
+            unknown = append(unknown, skill)
+        }
+    }
+
+    c.JSON(http.StatusOK, gin.H{"skills": strings.Join(skills, ", "), "unknown": unknown})
+}
+
+// GetSkillFrequency counts how many of a position's candidates list each
+// skill, after normalizing their skills against the taxonomy.
+func GetSkillFrequency(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    positionID := c.Param("positionId")
+
+    var position models.Position
+    if err := config.DB.Preload("Department").First(&position, positionID).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
+        return
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this position"})
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    var skillLists []string
+    if err := config.DB.Model(&models.Candidate{}).Where("position_id = ?", position.ID).Pluck("skills", &skillLists).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve candidates", "error": err.Error()})
+        return
+    }
+
+    counts := make(map[string]int)
+    for _, list := range skillLists {
+        for _, skill := range taxonomy.Normalize(list) {
+            counts[skill]++
+        }
+    }
+
+    frequencies := make([]SkillFrequency, 0, len(counts))
+    for name, count := range counts {
+        frequency := SkillFrequency{Skill: name, Count: count}
+        if skill, ok := taxonomy.Lookup(name); ok {
+            frequency.Category = skill.Category
+            frequency.Known = true
+        }
+        frequencies = append(frequencies, frequency)
+    }
+    sort.Slice(frequencies, func(i, j int) bool {
+        if frequencies[i].Count != frequencies[j].Count {
+            return frequencies[i].Count > frequencies[j].Count
+        }
+        return frequencies[i].Skill < frequencies[j].Skill
+    })
+
+    c.JSON(http.StatusOK, gin.H{"positionId": position.ID, "candidates": len(skillLists), "skills": frequencies})
+}
+
+// applySkillInput validates the input against the rest of the taxonomy and
+// copies it onto skill. It returns the HTTP status to respond with on error.
+func applySkillInput(db *gorm.DB, skill *models.Skill, input SkillInput) (int, error) {
+    normalized := utils.NormalizeSkill(input.Name)
+    if normalized == "" {
+        return http.StatusBadRequest, errors.New("Skill name is required")
+    }
+
+    taxonomy, err := loadSkillTaxonomy(db, skill.CompanyID)
+    if err != nil {
+        return http.StatusInternalServerError, err
+    }
+
+    names := append([]string{input.Name}, input.Aliases...)
+    for _, name := range names {
+        if existing, ok := taxonomy.Lookup(name); ok && existing.ID != skill.ID {
+            return http.StatusConflict, fmt.Errorf("%q already belongs to skill %s", name, existing.Name)
+        }
+    }
+
+    if input.ParentID != nil {
+        parent, ok := taxonomy.byID[*input.ParentID]
+        if !ok {
+            return http.StatusBadRequest, errors.New("Parent skill does not exist")
+        }
+        for ancestor := &parent; ancestor != nil; {
+            if ancestor.ID == skill.ID {
+                return http.StatusBadRequest, errors.New("A skill cannot be its own ancestor")
+            }
+            if ancestor.ParentID == nil {
+                break
+            }
+            next, ok := taxonomy.byID[*ancestor.ParentID]
+            if !ok {
+                break
+            }
+            ancestor = &next
+        }
+    }
+
+    skill.Name = strings.TrimSpace(input.Name)
+    skill.NormalizedName = normalized
+    skill.Category = input.Category
+    skill.ParentID = input.ParentID
+    return http.StatusOK, nil
+}
+
+func replaceSkillAliases(tx *gorm.DB, skill *models.Skill, aliases []string) error {
+    if err := tx.Where("skill_id = ?", skill.ID).Delete(&models.SkillAlias{}).Error; err != nil {
+        return err
+    }
+
+    seen := map[string]bool{skill.NormalizedName: true}
+    skill.Aliases = nil
+    for _, alias := range aliases {
+        normalized := utils.NormalizeSkill(alias)
+        if normalized == "" || seen[normalized] {
+            continue
+        }
+        seen[normalized] = true
+        skill.Aliases = append(skill.Aliases, models.SkillAlias{SkillID: skill.ID, Alias: normalized, CompanyID: skill.CompanyID})
+    }
+
+    if len(skill.Aliases) == 0 {
+        return nil
+    }
+    return tx.Create(&skill.Aliases).Error
+}
+
+// skillTaxonomy resolves skill names and aliases of one company to their
+// canonical skills.
+type skillTaxonomy struct {
+    byKey map[string]models.Skill
+    byID  map[uint]models.Skill
+}
+
+func loadSkillTaxonomy(db *gorm.DB, companyID uint) (skillTaxonomy, error) {
+    taxonomy := skillTaxonomy{byKey: make(map[string]models.Skill), byID: make(map[uint]models.Skill)}
+
+    var skills []models.Skill
+    if err := db.Preload("Aliases").Where("company_id = ?", companyID).Find(&skills).Error; err != nil {
+        return taxonomy, err
+    }
+
+    for _, skill := range skills {
+        taxonomy.byID[skill.ID] = skill
+        taxonomy.byKey[skill.NormalizedName] = skill
+        for _, alias := range skill.Aliases {
+            taxonomy.byKey[alias.Alias] = skill
+        }
+    }
+    return taxonomy, nil
+}
+
+// Empty reports whether the company has not defined any skills yet.
+func (t skillTaxonomy) Empty() bool {
+    return len(t.byID) == 0
+}
+
+// Lookup returns the canonical skill for a name or alias.
+func (t skillTaxonomy) Lookup(name string) (models.Skill, bool) {
+    skill, ok := t.byKey[utils.NormalizeSkill(name)]
+    return skill, ok
+}
+
+// Normalize splits a free-text skill list and maps every entry onto its
+// canonical name. Unknown skills are kept as entered, trimmed; duplicates are
+// dropped.
+func (t skillTaxonomy) Normalize(skills string) []string {
+    seen := make(map[string]bool)
+    var result []string
+    for _, raw := range utils.SplitSkills(skills) {
+        name := strings.Join(strings.Fields(raw), " ")
+        key := utils.NormalizeSkill(raw)
+        if skill, ok := t.byKey[key]; ok {
+            name, key = skill.Name, skill.NormalizedName
+        }
+        if key == "" || seen[key] {
+            continue
+        }
+        seen[key] = true
+        result = append(result, name)
+    }
+    return result
+}
+
+// Find returns the canonical names of the taxonomy skills mentioned in text,
+// matching whole words of their names and aliases.
+func (t skillTaxonomy) Find(text string) []string {
+    textKey := utils.PhraseKey(text)
+
+    var found []string
+    for _, skill := range t.byID {
+        terms := []string{skill.Name}
+        for _, alias := range skill.Aliases {
+            terms = append(terms, alias.Alias)
+        }
+        for _, term := range terms {
+            if key := utils.PhraseKey(term); strings.TrimSpace(key) != "" && strings.Contains(textKey, key) {
+                found = append(found, skill.Name)
+                break
+            }
+        }
+    }
+    sort.Strings(found)
+    return found
+}
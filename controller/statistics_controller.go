@@ -0,0 +1,157 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "math"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// scoreBucketWidth is the width of each bar of the score histogram, which
+// covers scores from 0 to 100.
+const scoreBucketWidth = 10
+
+// CandidateStatistics summarises the candidates of a position or department.
+// Candidates without a scored date have not been scored, so the score
+// figures only cover the others.
+type CandidateStatistics struct {
+    Uploaded       int64            `json:"uploaded"`
+    Scored         int64            `json:"scored"`
+    Qualified      int64            `json:"qualified"`
+    Stages         map[string]int64 `json:"stages"`
+    AverageScore   float64          `json:"averageScore"`
+    MedianScore    float64          `json:"medianScore"`
+    ScoreHistogram []ScoreBucket    `json:"scoreHistogram"`
+}
+
+// ScoreBucket counts the scored candidates with Min <= score < Max; the last
+// bucket also includes scores of exactly Max.
+type ScoreBucket struct {
+    Min   float64 `json:"min"`
+    Max   float64 `json:"max"`
+    Count int64   `json:"count"`
+}
+
+// GetPositionStatistics computes the candidate statistics of a position.
+func GetPositionStatistics(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var position models.Position
+    if err := config.DB.Preload("Department").First(&position, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
+        return
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this position"})
+        return
+    }
+
+    statistics, err := candidateStatistics(config.DB, func(db *gorm.DB) *gorm.DB {
+        return db.Where("position_id = ?", position.ID)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute statistics", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"positionId": position.ID, "statistics": statistics})
+}
+
+// GetDepartmentStatistics computes the candidate statistics of all positions
+// of a department.
+func GetDepartmentStatistics(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var department models.Department
+    if err := config.DB.First(&department, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Department does not exist"})
+        return
+    }
+
+    if department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this department"})
+        return
+    }
+
+    statistics, err := candidateStatistics(config.DB, func(db *gorm.DB) *gorm.DB {
+        return db.Where("position_id IN (SELECT id FROM positions WHERE department_id = ? AND deleted_at IS NULL)", department.ID)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to compute statistics", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"departmentId": department.ID, "statistics": statistics})
+}
+
+// candidateStatistics aggregates the candidates selected by scope.
+func candidateStatistics(db *gorm.DB, scope func(*gorm.DB) *gorm.DB) (CandidateStatistics, error) {
+    candidates := func() *gorm.DB {
+        return db.Model(&models.Candidate{}).Scopes(scope)
+    }
+
+    var totals struct {
+        Uploaded     int64
+        Scored       int64
+        Qualified    int64
+        AverageScore float64
+        MedianScore  float64
+    }
+    if err := candidates().Select(`COUNT(*) AS uploaded,
+        COUNT(*) FILTER (WHERE scored_date IS NOT NULL) AS scored,
+        COUNT(*) FILTER (WHERE is_qualified) AS qualified,
+        COALESCE(AVG(score) FILTER (WHERE scored_date IS NOT NULL), 0) AS average_score,
+        COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY score) FILTER (WHERE scored_date IS NOT NULL), 0) AS median_score`).
+        Scan(&totals).Error; err != nil {
+        return CandidateStatistics{}, err
+    }
+
+    var stages []struct {
+        Stage string
+        Count int64
+    }
+    if err := candidates().Select("stage, COUNT(*) AS count").Group("stage").Scan(&stages).Error; err != nil {
+        return CandidateStatistics{}, err
+    }
+
+    bucketCount := 100 / scoreBucketWidth
+    var buckets []struct {
+        Bucket int
+        Count  int64
+    }
+    if err := candidates().
+        Select("LEAST(FLOOR(score / ?), ?) AS bucket, COUNT(*) AS count", scoreBucketWidth, bucketCount-1).
+        Where("scored_date IS NOT NULL").
+        Group("bucket").
+        Scan(&buckets).Error; err != nil {
+        return CandidateStatistics{}, err
+    }
+
+    statistics := CandidateStatistics{
+        Uploaded:       totals.Uploaded,
+        Scored:         totals.Scored,
+        Qualified:      totals.Qualified,
+        Stages:         make(map[string]int64, len(models.Stages)),
+        AverageScore:   math.Round(totals.AverageScore*10) / 10,
+        MedianScore:    math.Round(totals.MedianScore*10) / 10,
+        ScoreHistogram: make([]ScoreBucket, bucketCount),
+    }
+    for _, stage := range models.Stages {
+        statistics.Stages[stage] = 0
+    }
+    for _, stage := range stages {
+        statistics.Stages[stage.Stage] = stage.Count
+    }
+    for i := range statistics.ScoreHistogram {
+        statistics.ScoreHistogram[i] = ScoreBucket{Min: float64(i * scoreBucketWidth), Max: float64((i + 1) * scoreBucketWidth)}
+    }
+    for _, bucket := range buckets {
+        statistics.ScoreHistogram[bucket.Bucket].Count = bucket.Count
+    }
+    return statistics, nil
+}
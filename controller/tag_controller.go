@@ -0,0 +1,202 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+type CreateTagInput struct {
+    Name  string `json:"name" binding:"required"`
+    Color string `json:"color"`
+}
+
+type EditTagInput struct {
+    Name  string `json:"name" binding:"required"`
+    Color string `json:"color"`
+}
+
+type CandidateTagsInput struct {
+    CandidateIDs []uint `json:"candidateIds" binding:"required,min=1"`
+    TagIDs       []uint `json:"tagIds" binding:"required,min=1"`
+}
+
+func CreateTag(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input CreateTagInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var existingTag models.Tag
+    if err := config.DB.Where("name = ? AND company_id = ?", input.Name, userClaims.CompanyID).First(&existingTag).Error; err == nil {
+        c.JSON(http.StatusConflict, gin.H{"message": "Tag already exists"})
+        return
+    }
+
+    tag := models.Tag{
+        Name:      input.Name,
+        Color:     input.Color,
+        CompanyID: userClaims.CompanyID,
+    }
+
+    if err := config.DB.Create(&tag).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create tag", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Tag created successfully", "tag": tag})
+}
+
+func GetAllTags(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "tags", utils.NameAndCreatedSort("tags"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.Tag{}).Where("company_id = ?", userClaims.CompanyID)
+    page, err := utils.Paginate[models.Tag](query, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve tags", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func EditTag(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+    var input EditTagInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var tag models.Tag
+    if err := config.DB.First(&tag, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Tag does not exist"})
+        return
+    }
+
+    if tag.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to edit this tag"})
+        return
+    }
+
+    var existingTag models.Tag
+    if err := config.DB.Where("name = ? AND company_id = ? AND id != ?", input.Name, tag.CompanyID, tag.ID).First(&existingTag).Error; err == nil {
+        c.JSON(http.StatusConflict, gin.H{"message": "Tag name already exists"})
+        return
+    }
+
+    tag.Name = input.Name
+    tag.Color = input.Color
+
+    if err := config.DB.Save(&tag).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update tag", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Tag updated successfully", "tag": tag})
+}
+
+func DeleteTag(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var tag models.Tag
+    if err := config.DB.First(&tag, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Tag does not exist"})
+        return
+    }
+
+    if tag.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to delete this tag"})
+        return
+    }
+
+    if err := config.DB.Delete(&tag).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete tag", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// AddTagsToCandidates applies every tag in the request to every candidate in
+// the request. Tags already applied to a candidate are left untouched.
+func AddTagsToCandidates(c *gin.Context) {
+    updateCandidateTags(c, true)
+}
+
+// RemoveTagsFromCandidates removes every tag in the request from every
+// candidate in the request.
+func RemoveTagsFromCandidates(c *gin.Context) {
+    updateCandidateTags(c, false)
+}
+
+func updateCandidateTags(c *gin.Context, add bool) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input CandidateTagsInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    candidateIDs := uniqueIDs(input.CandidateIDs)
+    tagIDs := uniqueIDs(input.TagIDs)
+
+    candidates, err := findCompanyCandidates(config.DB, userClaims.CompanyID, candidateIDs)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve candidates", "error": err.Error()})
+        return
+    }
+    if len(candidates) != len(candidateIDs) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to one or more of these candidates"})
+        return
+    }
+
+    var tags []models.Tag
+    if err := config.DB.Where("id IN ? AND company_id = ?", tagIDs, userClaims.CompanyID).Find(&tags).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve tags", "error": err.Error()})
+        return
+    }
+    if len(tags) != len(tagIDs) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to one or more of these tags"})
+        return
+    }
+
+    tx := config.DB.Begin()
+    for i := range candidates {
+        association := tx.Model(&candidates[i]).Association("Tags")
+        if add {
+            err = association.Append(tags)
+        } else {
+            err = association.Delete(tags)
+        }
+        if err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update candidate tags", "error": err.Error()})
+            return
+        }
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update candidate tags", "error": err.Error()})
+        return
+    }
+
+    message := "Tags added successfully"
+    if !add {
+        message = "Tags removed successfully"
+    }
+    c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -0,0 +1,208 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+type CreateTalentPoolInput struct {
+    Name        string `json:"name" binding:"required"`
+    Description string `json:"description"`
+}
+
+type EditTalentPoolInput struct {
+    Name        string `json:"name" binding:"required"`
+    Description string `json:"description"`
+}
+
+type TalentPoolCandidatesInput struct {
+    CandidateIDs []uint `json:"candidateIds" binding:"required,min=1"`
+}
+
+func CreateTalentPool(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input CreateTalentPoolInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var existingPool models.TalentPool
+    if err := config.DB.Where("name = ? AND company_id = ?", input.Name, userClaims.CompanyID).First(&existingPool).Error; err == nil {
+        c.JSON(http.StatusConflict, gin.H{"message": "Talent pool already exists"})
+        return
+    }
+
+    pool := models.TalentPool{
+        Name:        input.Name,
+        Description: input.Description,
+        CompanyID:   userClaims.CompanyID,
+    }
+
+    if err := config.DB.Create(&pool).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create talent pool", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Talent pool created successfully", "talentPool": pool})
+}
+
+func GetAllTalentPools(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "talent_pools", utils.NameAndCreatedSort("talent_pools"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.TalentPool{}).Where("company_id = ?", userClaims.CompanyID)
+    page, err := utils.Paginate[models.TalentPool](query, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve talent pools", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func GetOneTalentPool(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var pool models.TalentPool
+    if err := config.DB.Preload("Candidates.Position").Preload("Candidates.Tags").First(&pool, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Talent pool does not exist"})
+        return
+    }
+
+    if pool.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this talent pool"})
+        return
+    }
+
+    c.JSON(http.StatusOK, pool)
+}
+
+func EditTalentPool(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+    var input EditTalentPoolInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var pool models.TalentPool
+    if err := config.DB.First(&pool, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Talent pool does not exist"})
+        return
+    }
+
+    if pool.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to edit this talent pool"})
+        return
+    }
+
+    var existingPool models.TalentPool
+    if err := config.DB.Where("name = ? AND company_id = ? AND id != ?", input.Name, pool.CompanyID, pool.ID).First(&existingPool).Error; err == nil {
+        c.JSON(http.StatusConflict, gin.H{"message": "Talent pool name already exists"})
+        return
+    }
+
+    pool.Name = input.Name
+    pool.Description = input.Description
+
+    if err := config.DB.Save(&pool).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update talent pool", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Talent pool updated successfully", "talentPool": pool})
+}
+
+func DeleteTalentPool(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+
+    var pool models.TalentPool
+    if err := config.DB.First(&pool, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Talent pool does not exist"})
+        return
+    }
+
+    if pool.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to delete this talent pool"})
+        return
+    }
+
+    if err := config.DB.Delete(&pool).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete talent pool", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Talent pool deleted successfully"})
+}
+
+// AddCandidatesToTalentPool adds candidates from any of the company's
+// positions to the pool.
+func AddCandidatesToTalentPool(c *gin.Context) {
+    updateTalentPoolCandidates(c, true)
+}
+
+func RemoveCandidatesFromTalentPool(c *gin.Context) {
+    updateTalentPoolCandidates(c, false)
+}
+
+func updateTalentPoolCandidates(c *gin.Context, add bool) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    id := c.Param("id")
+    var input TalentPoolCandidatesInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    var pool models.TalentPool
+    if err := config.DB.First(&pool, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Talent pool does not exist"})
+        return
+    }
+
+    if pool.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to edit this talent pool"})
+        return
+    }
+
+    candidateIDs := uniqueIDs(input.CandidateIDs)
+    candidates, err := findCompanyCandidates(config.DB, userClaims.CompanyID, candidateIDs)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve candidates", "error": err.Error()})
+        return
+    }
+    if len(candidates) != len(candidateIDs) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to one or more of these candidates"})
+        return
+    }
+
+    association := config.DB.Model(&pool).Association("Candidates")
+    if add {
+        err = association.Append(candidates)
+    } else {
+        err = association.Delete(candidates)
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update talent pool", "error": err.Error()})
+        return
+    }
+
+    message := "Candidates added to talent pool successfully"
+    if !add {
+        message = "Candidates removed from talent pool successfully"
+    }
+    c.JSON(http.StatusOK, gin.H{"message": message})
+}
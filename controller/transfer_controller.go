@@ -0,0 +1,212 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/filestore"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    TransferMove = "move"
+    TransferCopy = "copy"
+)
+
+type TransferCandidateInput struct {
+    PositionID uint   `json:"positionId" binding:"required"`
+    Mode       string `json:"mode" binding:"required,oneof=move copy"`
+}
+
+// errDuplicateApplication stops a transfer to a position the candidate has
+// already applied for.
+var errDuplicateApplication = errors.New("candidate with this email already exists for the position")
+
+// errNotAcceptingApplications stops a transfer to a position that is not
+// open or whose application deadline has passed.
+var errNotAcceptingApplications = errors.New("position is not accepting applications")
+
+// TransferCandidate moves or copies a candidate to another position of the
+// same company without uploading the CV again. The CV file, extracted data,
+// notes and stage history come along, and the candidate is rescored against
+// the new position. A copy is a separate application that shares the stored
+// CV files with the original. The target position must accept applications,
+// and a hired candidate counts towards filling it.
+func TransferCandidate(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+    var input TransferCandidateInput
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input", "details": err.Error()})
+        return
+    }
+
+    candidate, ok := loadCompanyCandidate(c, c.Param("id"), "transfer")
+    if !ok {
+        return
+    }
+
+    var target models.Position
+    if err := config.DB.Preload("Department").First(&target, input.PositionID).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
+        return
+    }
+
+    if target.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to transfer candidates to this position"})
+        return
+    }
+
+    if target.ID == candidate.PositionID {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Candidate already belongs to this position"})
+        return
+    }
+
+    taxonomy, err := loadSkillTaxonomy(config.DB, userClaims.CompanyID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load skills", "error": err.Error()})
+        return
+    }
+
+    candidate.PositionID = target.ID
+    candidate.Position = target
+    candidate.SetScore(scoreCandidate(candidate, target, taxonomy).Score, time.Now())
+
+    err = config.DB.Transaction(func(tx *gorm.DB) error {
+        now := time.Now()
+        locked, err := lockPosition(tx, target.ID)
+        if err != nil {
+            return err
+        }
+        if !locked.AcceptsApplications(now) {
+            target.Status = locked.Status
+            return errNotAcceptingApplications
+        }
+
+        var existing int64
+        if err := tx.Model(&models.Candidate{}).
+            Where("email = ? AND position_id = ?", candidate.Email, target.ID).
+            Count(&existing).Error; err != nil {
+            return err
+        }
+        if existing > 0 {
+            return errDuplicateApplication
+        }
+
+        if input.Mode == TransferMove {
+            if err := tx.Model(&candidate).Select("position_id", "score", "scored_date").Updates(&candidate).Error; err != nil {
+                return err
+            }
+        } else {
+            sourceID := candidate.ID
+            candidate.ID = 0
+            candidate.CreatedDate = now
+            if err := tx.Omit(clause.Associations).Create(&candidate).Error; err != nil {
+                return err
+            }
+            if err := copyCandidateRecords(tx, sourceID, candidate.ID); err != nil {
+                return err
+            }
+        }
+
+        if candidate.Stage != models.StageHired {
+            return nil
+        }
+        if err := autoClosePosition(tx, &locked, now); err != nil {
+            return err
+        }
+        candidate.Position.Status, candidate.Position.StatusDate = locked.Status, locked.StatusDate
+        return nil
+    })
+    if err == errNotAcceptingApplications {
+        c.JSON(http.StatusConflict, gin.H{"message": "Position is not accepting applications", "status": target.Status})
+        return
+    }
+    if err == errDuplicateApplication {
+        c.JSON(http.StatusConflict, gin.H{"message": "Candidate already exists for this position"})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to transfer candidate", "error": err.Error()})
+        return
+    }
+
+    if input.Mode == TransferCopy {
+        refreshEmbedding(models.EmbeddingOwnerCandidate, candidate.ID, candidateEmbeddingText(candidate))
+    }
+
+    message := "Candidate moved successfully"
+    if input.Mode == TransferCopy {
+        message = "Candidate copied successfully"
+    }
+    c.JSON(http.StatusOK, gin.H{"message": message, "candidate": candidate})
+}
+
+// copyCandidateRecords duplicates the records hanging off one candidate onto
+// another: CV versions, with a new reference to each of their files, OCR
+// pages, extracted fields and reviews, skill experience, notes and stage
+// history. Timestamps are kept so the copy's history reads like the
+// original's.
+func copyCandidateRecords(tx *gorm.DB, fromID, toID uint) error {
+    var versions []models.CandidateCVVersion
+    err := copyRows(tx, fromID, &versions, func(v *models.CandidateCVVersion) {
+        v.ID, v.CandidateID = 0, toID
+    })
+    if err != nil {
+        return err
+    }
+    for _, version := range versions {
+        if err := filestore.Retain(tx, version.CVFile, version.ThumbnailURL); err != nil {
+            return err
+        }
+    }
+
+    if err := copyRows(tx, fromID, &[]models.CandidateOCRPage{}, func(p *models.CandidateOCRPage) {
+        p.ID, p.CandidateID = 0, toID
+    }); err != nil {
+        return err
+    }
+    if err := copyRows(tx, fromID, &[]models.CandidateFieldExtraction{}, func(e *models.CandidateFieldExtraction) {
+        e.ID, e.CandidateID = 0, toID
+    }); err != nil {
+        return err
+    }
+    if err := copyRows(tx, fromID, &[]models.CandidateFieldReview{}, func(r *models.CandidateFieldReview) {
+        r.ID, r.CandidateID = 0, toID
+    }); err != nil {
+        return err
+    }
+    if err := copyRows(tx, fromID, &[]models.CandidateSkillExperience{}, func(e *models.CandidateSkillExperience) {
+        e.ID, e.CandidateID = 0, toID
+    }); err != nil {
+        return err
+    }
+    if err := copyRows(tx, fromID, &[]models.CandidateNote{}, func(n *models.CandidateNote) {
+        n.ID, n.CandidateID = 0, toID
+    }); err != nil {
+        return err
+    }
+    return copyRows(tx, fromID, &[]models.CandidateStageHistory{}, func(h *models.CandidateStageHistory) {
+        h.ID, h.CandidateID = 0, toID
+    })
+}
+
+// copyRows loads the rows of one candidate into rows, lets reassign point
+// each at the new candidate, and inserts them.
+func copyRows[T any](tx *gorm.DB, fromID uint, rows *[]T, reassign func(*T)) error {
+    if err := tx.Where("candidate_id = ?", fromID).Order("id").Find(rows).Error; err != nil {
+        return err
+    }
+    if len(*rows) == 0 {
+        return nil
+    }
+    for i := range *rows {
+        reassign(&(*rows)[i])
+    }
+    return tx.Create(rows).Error
+}
@@ -0,0 +1,318 @@
+package controller
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "log"
+    "net/http"
+    "os"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// defaultTrashRetentionDays is how long deleted candidates, positions and
+// departments stay restorable unless TRASH_RETENTION_DAYS says otherwise.
+const defaultTrashRetentionDays = 30
+
+// trashRetention returns how long deleted rows are kept before they are
+// purged along with their CV files.
+func trashRetention() time.Duration {
+    days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+    if err != nil || days < 0 {
+        days = defaultTrashRetentionDays
+    }
+    return time.Duration(days) * 24 * time.Hour
+}
+
+// trashSortFields adds sorting by deletion date to the usual sort keys.
+func trashSortFields(table string) map[string]utils.SortField {
+    fields := utils.NameAndCreatedSort(table)
+    fields["deleted"] = utils.SortField{Column: table + ".deleted_at", Field: "DeletedAt", Kind: utils.SortTime}
+    return fields
+}
+
+// GetDeletedCandidates lists the company's candidates that were deleted on
+// their own. Candidates deleted along with their position are restored with
+// it and appear under GetDeletedPositions instead.
+func GetDeletedCandidates(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "candidates", trashSortFields("candidates"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Unscoped().Model(&models.Candidate{}).
+        Joins("JOIN positions ON candidates.position_id = positions.id").
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("departments.company_id = ?", userClaims.CompanyID).
+        Where("candidates.deleted_at IS NOT NULL AND positions.deleted_at IS NULL")
+    page, err := utils.Paginate[models.Candidate](query, params, "Position")
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve deleted candidates", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+// GetDeletedPositions lists the company's positions that were deleted on
+// their own, rather than along with their department.
+func GetDeletedPositions(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "positions", trashSortFields("positions"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Unscoped().Model(&models.Position{}).
+        Joins("JOIN departments ON positions.department_id = departments.id").
+        Where("departments.company_id = ?", userClaims.CompanyID).
+        Where("positions.deleted_at IS NOT NULL AND departments.deleted_at IS NULL")
+    page, err := utils.Paginate[models.Position](query, params, "Department")
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve deleted positions", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func GetDeletedDepartments(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    params, err := utils.ParseListParams(c, "departments", trashSortFields("departments"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Unscoped().Model(&models.Department{}).
+        Where("departments.company_id = ? AND departments.deleted_at IS NOT NULL", userClaims.CompanyID)
+    page, err := utils.Paginate[models.Department](query, params)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve deleted departments", "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, page)
+}
+
+func RestoreCandidate(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var candidate models.Candidate
+    if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&candidate, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Deleted candidate does not exist"})
+        return
+    }
+
+    var position models.Position
+    if err := config.DB.Unscoped().Preload("Department").First(&position, candidate.PositionID).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Position does not exist"})
+        return
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to restore this candidate"})
+        return
+    }
+
+    if position.DeletedAt.Valid {
+        c.JSON(http.StatusConflict, gin.H{"message": "The candidate's position is deleted, restore the position instead"})
+        return
+    }
+
+    var existing models.Candidate
+    if err := config.DB.Where("email = ? AND position_id = ?", candidate.Email, candidate.PositionID).First(&existing).Error; err == nil {
+        c.JSON(http.StatusConflict, gin.H{"message": "Another candidate with this email has applied for the position since", "candidateId": existing.ID})
+        return
+    }
+
+    if err := config.DB.Unscoped().Model(&candidate).Update("deleted_at", nil).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to restore candidate", "error": err.Error()})
+        return
+    }
+
+    candidate.Position = position
+    c.JSON(http.StatusOK, gin.H{"message": "Candidate restored successfully", "candidate": candidate})
+}
+
+// RestorePosition restores a deleted position and the candidates deleted
+// along with it.
+func RestorePosition(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var position models.Position
+    if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").Preload("Department").First(&position, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Deleted position does not exist"})
+        return
+    }
+
+    if position.Department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to restore this position"})
+        return
+    }
+
+    if position.Department.DeletedAt.Valid {
+        c.JSON(http.StatusConflict, gin.H{"message": "The position's department is deleted, restore the department instead"})
+        return
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        return restorePositions(tx, []uint{position.ID}, position.DeletedAt.Time)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to restore position", "error": err.Error()})
+        return
+    }
+
+    position.DeletedAt = gorm.DeletedAt{}
+    c.JSON(http.StatusOK, gin.H{"message": "Position restored successfully", "position": position})
+}
+
+// RestoreDepartment restores a deleted department and the positions and
+// candidates deleted along with it.
+func RestoreDepartment(c *gin.Context) {
+    userClaims := c.MustGet("claims").(*utils.Claims)
+
+    var department models.Department
+    if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&department, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"message": "Deleted department does not exist"})
+        return
+    }
+
+    if department.CompanyID != userClaims.CompanyID {
+        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to restore this department"})
+        return
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        var positionIDs []uint
+        if err := tx.Unscoped().Model(&models.Position{}).
+            Where("department_id = ? AND deleted_at = ?", department.ID, department.DeletedAt.Time).
+            Pluck("id", &positionIDs).Error; err != nil {
+            return err
+        }
+        if err := restorePositions(tx, positionIDs, department.DeletedAt.Time); err != nil {
+            return err
+        }
+        return tx.Unscoped().Model(&department).Update("deleted_at", nil).Error
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to restore department", "error": err.Error()})
+        return
+    }
+
+    department.DeletedAt = gorm.DeletedAt{}
+    c.JSON(http.StatusOK, gin.H{"message": "Department restored successfully", "department": department})
+}
+
+// trashPositions soft-deletes positions together with their candidates,
+// stamping them all with deletedAt so that restoring a position brings back
+// exactly the candidates deleted with it, and not those deleted earlier.
+func trashPositions(tx *gorm.DB, positionIDs []uint, deletedAt time.Time) error {
+    if len(positionIDs) == 0 {
+        return nil
+    }
+    if err := tx.Model(&models.Candidate{}).Where("position_id IN ?", positionIDs).Update("deleted_at", deletedAt).Error; err != nil {
+        return err
+    }
+    return tx.Model(&models.Position{}).Where("id IN ?", positionIDs).Update("deleted_at", deletedAt).Error
+}
+
+// restorePositions undoes trashPositions for positions deleted at deletedAt.
+func restorePositions(tx *gorm.DB, positionIDs []uint, deletedAt time.Time) error {
+    if len(positionIDs) == 0 {
+        return nil
+    }
+    if err := tx.Unscoped().Model(&models.Candidate{}).
+        Where("position_id IN ? AND deleted_at = ?", positionIDs, deletedAt).
+        Update("deleted_at", nil).Error; err != nil {
+        return err
+    }
+    return tx.Unscoped().Model(&models.Position{}).
+        Where("id IN ? AND deleted_at = ?", positionIDs, deletedAt).
+        Update("deleted_at", nil).Error
+}
+
+// RunTrashPurger purges expired trash once a day until the process exits.
+func RunTrashPurger() {
+    for {
+        if err := PurgeDeleted(time.Now().Add(-trashRetention())); err != nil {
+            log.Printf("Failed to purge deleted records: %v", err)
+        }
+        time.Sleep(24 * time.Hour)
+    }
+}
+
+// PurgeDeleted permanently deletes the departments, positions and candidates
+// deleted before the cutoff, together with everything under them. Their CV
+// files are released so storage cleanup removes them.
+func PurgeDeleted(before time.Time) error {
+    return config.DB.Transaction(func(tx *gorm.DB) error {
+        var departmentIDs, positionIDs, candidateIDs []uint
+        if err := tx.Unscoped().Model(&models.Department{}).
+            Where("deleted_at < ?", before).
+            Pluck("id", &departmentIDs).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Model(&models.Position{}).
+            Where("deleted_at < ?", before).
+            Or("department_id IN ?", departmentIDs).
+            Pluck("id", &positionIDs).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Model(&models.Candidate{}).
+            Where("deleted_at < ?", before).
+            Or("position_id IN ?", positionIDs).
+            Pluck("id", &candidateIDs).Error; err != nil {
+            return err
+        }
+
+        if err := purgeCandidates(tx, candidateIDs); err != nil {
+            return err
+        }
+        if len(positionIDs) > 0 {
+            if err := deleteEmbeddings(tx, models.EmbeddingOwnerPosition, positionIDs); err != nil {
+                return err
+            }
+            if err := tx.Unscoped().Delete(&models.Position{}, positionIDs).Error; err != nil {
+                return err
+            }
+        }
+        if len(departmentIDs) > 0 {
+            if err := tx.Unscoped().Delete(&models.Department{}, departmentIDs).Error; err != nil {
+                return err
+            }
+        }
+
+        if len(candidateIDs)+len(positionIDs)+len(departmentIDs) > 0 {
+            log.Printf("Purged %d departments, %d positions and %d candidates deleted before %s",
+                len(departmentIDs), len(positionIDs), len(candidateIDs), before.Format(time.RFC3339))
+        }
+        return nil
+    })
+}
+
+// purgeCandidates permanently deletes candidates, releasing their CV files
+// and dropping their embeddings.
+func purgeCandidates(tx *gorm.DB, candidateIDs []uint) error {
+    if len(candidateIDs) == 0 {
+        return nil
+    }
+    if err := releaseCandidateFiles(tx, candidateIDs); err != nil {
+        return err
+    }
+    if err := deleteEmbeddings(tx, models.EmbeddingOwnerCandidate, candidateIDs); err != nil {
+        return err
+    }
+    return tx.Unscoped().Delete(&models.Candidate{}, candidateIDs).Error
+}
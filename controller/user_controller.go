@@ -113,10 +113,17 @@ func DeleteUser(c *gin.Context) {
 // GetAllUsers retrieves all users
 func GetAllUsers(c *gin.Context) {
     userClaims := c.MustGet("claims").(*utils.Claims)
-    var users []models.User
-    if err := config.DB.Where("company_id = ?", userClaims.CompanyID).Find(&users).Error; err != nil {
+    params, err := utils.ParseListParams(c, "users", utils.NameAndCreatedSort("users"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list parameters", "details": err.Error()})
+        return
+    }
+
+    query := config.DB.Model(&models.User{}).Where("company_id = ?", userClaims.CompanyID)
+    page, err := utils.Paginate[models.User](query, params)
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users", "details": err.Error()})
         return
     }
-    c.JSON(http.StatusOK, users)
+    c.JSON(http.StatusOK, page)
 }
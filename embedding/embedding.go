@@ -0,0 +1,87 @@
+package embedding
+
+import (
+    "fmt"
+    "log"
+    "math"
+    "os"
+    "sync"
+)
+
+// Provider turns text into a fixed-size vector whose cosine similarity to
+// other vectors from the same provider reflects how related the texts are.
+// Vectors from different providers are not comparable, so stored vectors are
+// keyed by Name.
+type Provider interface {
+    Name() string
+    Dimensions() int
+    Embed(text string) ([]float32, error)
+}
+
+var (
+    defaultProvider     Provider
+    defaultProviderOnce sync.Once
+)
+
+// Default returns the provider selected by the EMBEDDING_PROVIDER environment
+// variable. Only the offline "hashed" provider is built in, and it is used
+// when the variable is unset.
+func Default() Provider {
+    defaultProviderOnce.Do(func() {
+        provider, err := New(os.Getenv("EMBEDDING_PROVIDER"))
+        if err != nil {
+            // Fail closed: falling back to another provider would mix its
+            // vectors with those of the one configured.
+            log.Printf("Embeddings are disabled: %v", err)
+            provider = unavailableProvider{err: err}
+        }
+        defaultProvider = provider
+    })
+    return defaultProvider
+}
+
+// New returns the provider with the given name.
+func New(name string) (Provider, error) {
+    switch name {
+    case "", "hashed":
+        return NewHashedProvider(defaultHashedDimensions), nil
+    default:
+        return nil, fmt.Errorf("unknown embedding provider %q", name)
+    }
+}
+
+// unavailableProvider fails every embedding with the configuration error.
+type unavailableProvider struct {
+    err error
+}
+
+func (p unavailableProvider) Name() string {
+    return "unavailable"
+}
+
+func (p unavailableProvider) Dimensions() int {
+    return 0
+}
+
+func (p unavailableProvider) Embed(text string) ([]float32, error) {
+    return nil, p.err
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 when their sizes
+// differ or either is all zeros.
+func Cosine(a, b []float32) float64 {
+    if len(a) != len(b) || len(a) == 0 {
+        return 0
+    }
+
+    var dot, normA, normB float64
+    for i := range a {
+        dot += float64(a[i]) * float64(b[i])
+        normA += float64(a[i]) * float64(a[i])
+        normB += float64(b[i]) * float64(b[i])
+    }
+    if normA == 0 || normB == 0 {
+        return 0
+    }
+    return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,128 @@
+package embedding
+
+import (
+    "fmt"
+    "hash/fnv"
+    "math"
+    "strings"
+    "unicode"
+
+    "cv-extractor/extractor"
+)
+
+const defaultHashedDimensions = 1024
+
+// synonyms maps common spellings and abbreviations to one canonical token so
+// that "Golang" and "Go", or "k8s" and "Kubernetes", land on the same feature.
+var synonyms = map[string]string{
+    "golang":      "go",
+    "k8s":         "kubernetes",
+    "js":          "javascript",
+    "ecmascript":  "javascript",
+    "ts":          "typescript",
+    "py":          "python",
+    "postgres":    "postgresql",
+    "psql":        "postgresql",
+    "mongo":       "mongodb",
+    "nodejs":      "node",
+    "node.js":     "node",
+    "reactjs":     "react",
+    "react.js":    "react",
+    "vuejs":       "vue",
+    "vue.js":      "vue",
+    "ml":          "machine-learning",
+    "ai":          "artificial-intelligence",
+    "gcp":         "google-cloud",
+    "aws":         "amazon-web-services",
+    "ci/cd":       "cicd",
+    "ux":          "user-experience",
+    "ui":          "user-interface",
+    "dotnet":      ".net",
+    "csharp":      "c#",
+    "cpp":         "c++",
+    "pengembang":  "developer",
+    "pemrograman": "programming",
+}
+
+// HashedProvider is an offline provider that hashes unigrams and bigrams of
+// the normalized text into a fixed number of buckets, weighting each feature
+// by sublinear term frequency. A second hash picks each feature's sign so that
+// bucket collisions cancel out on average instead of accumulating.
+type HashedProvider struct {
+    dimensions int
+}
+
+func NewHashedProvider(dimensions int) *HashedProvider {
+    return &HashedProvider{dimensions: dimensions}
+}
+
+func (p *HashedProvider) Name() string {
+    return fmt.Sprintf("hashed-%d", p.dimensions)
+}
+
+func (p *HashedProvider) Dimensions() int {
+    return p.dimensions
+}
+
+func (p *HashedProvider) Embed(text string) ([]float32, error) {
+    tokens := Tokenize(text)
+
+    counts := make(map[string]int)
+    for i, token := range tokens {
+        counts[token]++
+        if i > 0 {
+            counts[tokens[i-1]+" "+token]++
+        }
+    }
+
+    vector := make([]float32, p.dimensions)
+    for feature, count := range counts {
+        h := fnv.New64a()
+        h.Write([]byte(feature))
+        sum := h.Sum64()
+
+        weight := 1 + math.Log(float64(count))
+        if strings.Contains(feature, " ") {
+            weight *= 0.5
+        }
+        if sum>>63 == 1 {
+            weight = -weight
+        }
+        vector[sum%uint64(p.dimensions)] += float32(weight)
+    }
+
+    var norm float64
+    for _, v := range vector {
+        norm += float64(v) * float64(v)
+    }
+    if norm > 0 {
+        norm = math.Sqrt(norm)
+        for i := range vector {
+            vector[i] = float32(float64(vector[i]) / norm)
+        }
+    }
+
+    return vector, nil
+}
+
+// Tokenize lowercases text, splits it into words, drops stop words and maps
+// synonyms onto their canonical token. Characters common in technology names
+// such as "+", "#" and "." are kept inside words.
+func Tokenize(text string) []string {
+    words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#' && r != '.' && r != '/'
+    })
+
+    var tokens []string
+    for _, word := range words {
+        word = strings.Trim(word, "./")
+        if word == "" || extractor.IsStopWord(word) {
+            continue
+        }
+        if canonical, ok := synonyms[word]; ok {
+            word = canonical
+        }
+        tokens = append(tokens, word)
+    }
+    return tokens
+}
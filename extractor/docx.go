@@ -0,0 +1,79 @@
+package extractor
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/xml"
+    "errors"
+    "io"
+    "strings"
+)
+
+// errDOCXTooLarge rejects a document part that would inflate past
+// maxInflatedTotal.
+var errDOCXTooLarge = errors.New("DOCX document part is too large")
+
+// extractDOCXText reads the paragraphs of word/document.xml, emitting one line
+// per paragraph. Like PDF streams, the part may inflate to at most
+// maxInflatedTotal bytes.
+func extractDOCXText(data []byte) (string, error) {
+    archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+    if err != nil {
+        return "", err
+    }
+
+    for _, file := range archive.File {
+        if file.Name != "word/document.xml" {
+            continue
+        }
+        // The declared size can lie, so the stream is cut at the limit too.
+        if file.UncompressedSize64 > maxInflatedTotal {
+            return "", errDOCXTooLarge
+        }
+
+        rc, err := file.Open()
+        if err != nil {
+            return "", err
+        }
+        defer rc.Close()
+
+        var b strings.Builder
+        decoder := xml.NewDecoder(io.LimitReader(rc, maxInflatedTotal))
+        inText := false
+        for {
+            token, err := decoder.Token()
+            if err == io.EOF {
+                break
+            }
+            if err != nil {
+                return "", err
+            }
+
+            switch t := token.(type) {
+            case xml.StartElement:
+                switch t.Name.Local {
+                case "t":
+                    inText = true
+                case "tab":
+                    b.WriteString(" ")
+                case "br":
+                    b.WriteString("\n")
+                }
+            case xml.EndElement:
+                switch t.Name.Local {
+                case "t":
+                    inText = false
+                case "p":
+                    b.WriteString("\n")
+                }
+            case xml.CharData:
+                if inText {
+                    b.Write(t)
+                }
+            }
+        }
+        return b.String(), nil
+    }
+
+    return "", errors.New("document has no word/document.xml")
+}
@@ -0,0 +1,230 @@
+package extractor
+
+import (
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// monthNames maps month names and abbreviations in English, Indonesian,
+// Dutch, German, French and Spanish to their month number.
+var monthNames = map[string]int{
+    "january": 1, "jan": 1, "januari": 1, "januar": 1, "janvier": 1, "janv": 1, "enero": 1, "ene": 1,
+    "february": 2, "feb": 2, "februari": 2, "februar": 2, "février": 2, "fevrier": 2, "févr": 2, "fevr": 2, "febrero": 2, "peb": 2, "pebruari": 2,
+    "march": 3, "mar": 3, "maret": 3, "maart": 3, "mrt": 3, "märz": 3, "marz": 3, "mär": 3, "mrz": 3, "mars": 3, "marzo": 3,
+    "april": 4, "apr": 4, "avril": 4, "avr": 4, "abril": 4, "abr": 4,
+    "may": 5, "mei": 5, "mai": 5, "mayo": 5,
+    "june": 6, "jun": 6, "juni": 6, "juin": 6, "junio": 6,
+    "july": 7, "jul": 7, "juli": 7, "juillet": 7, "juil": 7, "julio": 7,
+    "august": 8, "aug": 8, "agustus": 8, "agu": 8, "agt": 8, "ags": 8, "augustus": 8, "août": 8, "aout": 8, "agosto": 8, "ago": 8,
+    "september": 9, "sep": 9, "sept": 9, "septembre": 9, "septiembre": 9,
+    "october": 10, "oct": 10, "oktober": 10, "okt": 10, "octobre": 10, "octubre": 10,
+    "november": 11, "nov": 11, "nopember": 11, "nop": 11, "novembre": 11, "noviembre": 11,
+    "december": 12, "dec": 12, "desember": 12, "des": 12, "dezember": 12, "dez": 12, "décembre": 12, "decembre": 12, "diciembre": 12, "dic": 12,
+}
+
+// presentWords mark a range that is still ongoing.
+var presentWords = []string{
+    "present", "now", "current", "currently", "today", "ongoing",
+    "sekarang", "saat ini", "kini", "hingga kini", "sampai sekarang",
+    "heden", "nu", "heute", "aktuell", "maintenant", "actuel", "presente", "actualidad", "actual",
+}
+
+var (
+    dateTokenPattern      = buildDateTokenPattern()
+    rangeSeparatorPattern = regexp.MustCompile(`(?i)^(?:-|–|—|~|to|until|till|through|s/d|s\.d\.?|sd|sampai|hingga|bis|tot|au|à|a|hasta)$`)
+)
+
+func buildDateTokenPattern() *regexp.Regexp {
+    months := make([]string, 0, len(monthNames))
+    for name := range monthNames {
+        months = append(months, regexp.QuoteMeta(name))
+    }
+    sort.Slice(months, func(i, j int) bool { return len(months[i]) > len(months[j]) })
+
+    present := make([]string, 0, len(presentWords))
+    for _, word := range presentWords {
+        present = append(present, regexp.QuoteMeta(word))
+    }
+    sort.Slice(present, func(i, j int) bool { return len(present[i]) > len(present[j]) })
+
+    return regexp.MustCompile(`(?i)\b(?:` +
+        `(\d{1,2})[/.-](\d{1,2})[/.-](\d{4})` +
+        `|(` + strings.Join(months, "|") + `)\.?,?\s+(\d{4})` +
+        `|(\d{1,2})[/.](\d{4})` +
+        `|(\d{4})[/.-](\d{1,2})` +
+        `|(\d{4})` +
+        `|(` + strings.Join(present, "|") + `)` +
+        `)\b`)
+}
+
+// WorkPeriod is one dated entry of a CV's work history. Start and End are the
+// first days of the first and last month worked; Text is the entry's line and
+// the description lines following it.
+type WorkPeriod struct {
+    Start   time.Time
+    End     time.Time
+    Current bool
+    Text    string
+}
+
+// Months returns the number of calendar months the period covers.
+func (p WorkPeriod) Months() int {
+    return monthIndex(p.End) - monthIndex(p.Start) + 1
+}
+
+type dateToken struct {
+    start, end int
+    year       int
+    month      int
+    present    bool
+}
+
+// ParseWorkHistory finds the dated entries of the work experience section of
+// a CV, or of the whole text when no such section is recognized. Ranges may
+// use month names, numeric dates or years alone, and may end in a word such
+// as "present" or "sekarang"; ranges ending in the future are capped at now.
+// The language decides whether ambiguous numeric dates such as 02/03/2020 are
+// read day first.
+func ParseWorkHistory(text, language string, now time.Time) []WorkPeriod {
+    if section := Sections(text)[SectionExperience]; strings.TrimSpace(section) != "" {
+        text = section
+    }
+
+    dayFirst := languages[language].dayFirst
+
+    lines := strings.Split(text, "\n")
+    var periods []WorkPeriod
+    var current *WorkPeriod
+    for _, line := range lines {
+        if period, ok := parseRange(line, dayFirst, now); ok {
+            if current != nil {
+                periods = append(periods, *current)
+            }
+            current = &period
+            continue
+        }
+        if current != nil {
+            current.Text += "\n" + line
+        }
+    }
+    if current != nil {
+        periods = append(periods, *current)
+    }
+    return periods
+}
+
+// parseRange returns the first date range found on a line.
+func parseRange(line string, dayFirst bool, now time.Time) (WorkPeriod, bool) {
+    tokens := dateTokens(line, dayFirst)
+    for i := 0; i+1 < len(tokens); i++ {
+        from, to := tokens[i], tokens[i+1]
+        if from.present {
+            continue
+        }
+        if !rangeSeparatorPattern.MatchString(strings.TrimSpace(line[from.end:to.start])) {
+            continue
+        }
+
+        start := time.Date(from.year, time.Month(max(from.month, 1)), 1, 0, 0, 0, 0, time.UTC)
+        thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+        var end time.Time
+        switch {
+        case to.present:
+            end = thisMonth
+        case to.month == 0:
+            end = time.Date(to.year, time.December, 1, 0, 0, 0, 0, time.UTC)
+        default:
+            end = time.Date(to.year, time.Month(to.month), 1, 0, 0, 0, 0, time.UTC)
+        }
+        if end.After(thisMonth) {
+            end = thisMonth
+        }
+        if start.After(end) {
+            continue
+        }
+
+        return WorkPeriod{Start: start, End: end, Current: to.present, Text: line}, true
+    }
+    return WorkPeriod{}, false
+}
+
+func dateTokens(line string, dayFirst bool) []dateToken {
+    var tokens []dateToken
+    for _, m := range dateTokenPattern.FindAllStringSubmatchIndex(line, -1) {
+        group := func(n int) string {
+            if m[2*n] < 0 {
+                return ""
+            }
+            return line[m[2*n]:m[2*n+1]]
+        }
+
+        token := dateToken{start: m[0], end: m[1]}
+        switch {
+        case group(1) != "":
+            first, _ := strconv.Atoi(group(1))
+            second, _ := strconv.Atoi(group(2))
+            token.year, _ = strconv.Atoi(group(3))
+            // Read the date the way the language writes it, unless only the
+            // other order gives a valid month.
+            if (dayFirst && second <= 12) || first > 12 {
+                token.month = second
+            } else {
+                token.month = first
+            }
+        case group(4) != "":
+            token.month = monthNames[strings.ToLower(group(4))]
+            token.year, _ = strconv.Atoi(group(5))
+        case group(6) != "":
+            token.month, _ = strconv.Atoi(group(6))
+            token.year, _ = strconv.Atoi(group(7))
+        case group(8) != "":
+            token.year, _ = strconv.Atoi(group(8))
+            token.month, _ = strconv.Atoi(group(9))
+        case group(10) != "":
+            token.year, _ = strconv.Atoi(group(10))
+        default:
+            token.present = true
+        }
+
+        if !token.present && (token.year < 1950 || token.year > 2100 || token.month > 12) {
+            continue
+        }
+        tokens = append(tokens, token)
+    }
+    return tokens
+}
+
+// TotalMonths returns the number of distinct months covered by the periods,
+// so overlapping jobs are only counted once.
+func TotalMonths(periods []WorkPeriod) int {
+    if len(periods) == 0 {
+        return 0
+    }
+
+    ranges := make([][2]int, len(periods))
+    for i, p := range periods {
+        ranges[i] = [2]int{monthIndex(p.Start), monthIndex(p.End)}
+    }
+    sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+    total := 0
+    current := ranges[0]
+    for _, r := range ranges[1:] {
+        if r[0] <= current[1]+1 {
+            current[1] = max(current[1], r[1])
+            continue
+        }
+        total += current[1] - current[0] + 1
+        current = r
+    }
+    total += current[1] - current[0] + 1
+    return total
+}
+
+func monthIndex(t time.Time) int {
+    return t.Year()*12 + int(t.Month()) - 1
+}
@@ -0,0 +1,202 @@
+package extractor
+
+import (
+    "testing"
+    "time"
+)
+
+func month(year int, m time.Month) time.Time {
+    return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDateTokens(t *testing.T) {
+    tests := []struct {
+        line     string
+        dayFirst bool
+        year     int
+        month    int
+        present  bool
+    }{
+        // Numeric dates follow the language's order unless only the other
+        // order gives a valid month.
+        {line: "02/03/2020", dayFirst: false, year: 2020, month: 2},
+        {line: "02/03/2020", dayFirst: true, year: 2020, month: 3},
+        {line: "13/03/2020", dayFirst: false, year: 2020, month: 3},
+        {line: "03/13/2020", dayFirst: true, year: 2020, month: 3},
+        {line: "02.03.2020", dayFirst: true, year: 2020, month: 3},
+        {line: "05/2019", year: 2019, month: 5},
+        {line: "2019-05", year: 2019, month: 5},
+        {line: "2019", year: 2019},
+
+        // Month names in each supported spelling.
+        {line: "Jan 2019", year: 2019, month: 1},
+        {line: "September, 2019", year: 2019, month: 9},
+        {line: "Agustus 2019", year: 2019, month: 8},
+        {line: "Maart 2019", year: 2019, month: 3},
+        {line: "Mrt. 2019", year: 2019, month: 3},
+        {line: "März 2019", year: 2019, month: 3},
+        {line: "Dezember 2019", year: 2019, month: 12},
+        {line: "juillet 2019", year: 2019, month: 7},
+        {line: "févr. 2019", year: 2019, month: 2},
+        {line: "diciembre 2019", year: 2019, month: 12},
+
+        // Words marking an ongoing range.
+        {line: "present", present: true},
+        {line: "Sekarang", present: true},
+        {line: "saat ini", present: true},
+        {line: "heden", present: true},
+        {line: "heute", present: true},
+        {line: "actualidad", present: true},
+    }
+    for _, test := range tests {
+        tokens := dateTokens(test.line, test.dayFirst)
+        if len(tokens) != 1 {
+            t.Errorf("dateTokens(%q, %v) = %+v, want one token", test.line, test.dayFirst, tokens)
+            continue
+        }
+        got := tokens[0]
+        if got.year != test.year || got.month != test.month || got.present != test.present {
+            t.Errorf("dateTokens(%q, %v) = %+v, want year %d month %d present %v",
+                test.line, test.dayFirst, got, test.year, test.month, test.present)
+        }
+    }
+}
+
+func TestDateTokensRejectsImplausibleDates(t *testing.T) {
+    for _, line := range []string{"1949", "2101", "2019-13", "call 0812"} {
+        if tokens := dateTokens(line, false); len(tokens) != 0 {
+            t.Errorf("dateTokens(%q) = %+v, want none", line, tokens)
+        }
+    }
+}
+
+func TestParseWorkHistory(t *testing.T) {
+    now := time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+    tests := []struct {
+        name     string
+        text     string
+        language string
+        want     []WorkPeriod
+    }{
+        {
+            name: "month names with description lines",
+            text: "Acme Corp, Jan 2018 - Mar 2020\nBuilt APIs\nGlobex, Apr 2020 - present",
+            want: []WorkPeriod{
+                {Start: month(2018, time.January), End: month(2020, time.March), Text: "Acme Corp, Jan 2018 - Mar 2020\nBuilt APIs"},
+                {Start: month(2020, time.April), End: month(2024, time.June), Current: true, Text: "Globex, Apr 2020 - present"},
+            },
+        },
+        {
+            name: "month first",
+            text: "01/02/2019 - 03/04/2020",
+            want: []WorkPeriod{{Start: month(2019, time.January), End: month(2020, time.March), Text: "01/02/2019 - 03/04/2020"}},
+        },
+        {
+            name:     "day first",
+            text:     "01/02/2019 - 03/04/2020",
+            language: LanguageIndonesian,
+            want:     []WorkPeriod{{Start: month(2019, time.February), End: month(2020, time.April), Text: "01/02/2019 - 03/04/2020"}},
+        },
+        {
+            name:     "Indonesian ongoing range",
+            text:     "PT Maju, Agustus 2021 s/d sekarang",
+            language: LanguageIndonesian,
+            want:     []WorkPeriod{{Start: month(2021, time.August), End: month(2024, time.June), Current: true, Text: "PT Maju, Agustus 2021 s/d sekarang"}},
+        },
+        {
+            name: "Dutch, German, French and Spanish ranges",
+            text: "maart 2015 tot mei 2016\nMärz 2016 bis heute\njanvier 2010 au juin 2011\nenero 2012 hasta diciembre 2012",
+            want: []WorkPeriod{
+                {Start: month(2015, time.March), End: month(2016, time.May), Text: "maart 2015 tot mei 2016"},
+                {Start: month(2016, time.March), End: month(2024, time.June), Current: true, Text: "März 2016 bis heute"},
+                {Start: month(2010, time.January), End: month(2011, time.June), Text: "janvier 2010 au juin 2011"},
+                {Start: month(2012, time.January), End: month(2012, time.December), Text: "enero 2012 hasta diciembre 2012"},
+            },
+        },
+        {
+            name: "years alone cover whole years",
+            text: "2015 - 2017",
+            want: []WorkPeriod{{Start: month(2015, time.January), End: month(2017, time.December), Text: "2015 - 2017"}},
+        },
+        {
+            name: "future end is capped at now",
+            text: "2023 - 2030",
+            want: []WorkPeriod{{Start: month(2023, time.January), End: month(2024, time.June), Text: "2023 - 2030"}},
+        },
+        {
+            name: "reversed range is ignored",
+            text: "2020 - 2018",
+        },
+        {
+            name: "only the experience section is read",
+            text: "Education\n2010 - 2014\nWork Experience\nAcme, 2015 - 2016",
+            want: []WorkPeriod{{Start: month(2015, time.January), End: month(2016, time.December), Text: "Acme, 2015 - 2016\n"}},
+        },
+    }
+    for _, test := range tests {
+        got := ParseWorkHistory(test.text, test.language, now)
+        if len(got) != len(test.want) {
+            t.Errorf("%s: ParseWorkHistory = %+v, want %+v", test.name, got, test.want)
+            continue
+        }
+        for i := range got {
+            if !got[i].Start.Equal(test.want[i].Start) || !got[i].End.Equal(test.want[i].End) ||
+                got[i].Current != test.want[i].Current || got[i].Text != test.want[i].Text {
+                t.Errorf("%s: period %d = %+v, want %+v", test.name, i, got[i], test.want[i])
+            }
+        }
+    }
+}
+
+func TestTotalMonths(t *testing.T) {
+    tests := []struct {
+        name    string
+        periods []WorkPeriod
+        want    int
+    }{
+        {name: "none", want: 0},
+        {
+            name:    "single month",
+            periods: []WorkPeriod{{Start: month(2020, time.May), End: month(2020, time.May)}},
+            want:    1,
+        },
+        {
+            name: "overlapping jobs count once",
+            periods: []WorkPeriod{
+                {Start: month(2018, time.June), End: month(2019, time.March)},
+                {Start: month(2018, time.January), End: month(2018, time.December)},
+            },
+            want: 15,
+        },
+        {
+            name: "job within another",
+            periods: []WorkPeriod{
+                {Start: month(2018, time.January), End: month(2020, time.December)},
+                {Start: month(2019, time.January), End: month(2019, time.June)},
+            },
+            want: 36,
+        },
+        {
+            name: "consecutive jobs",
+            periods: []WorkPeriod{
+                {Start: month(2018, time.January), End: month(2018, time.June)},
+                {Start: month(2018, time.July), End: month(2018, time.December)},
+            },
+            want: 12,
+        },
+        {
+            name: "gap between jobs",
+            periods: []WorkPeriod{
+                {Start: month(2019, time.January), End: month(2019, time.January)},
+                {Start: month(2018, time.January), End: month(2018, time.March)},
+            },
+            want: 4,
+        },
+    }
+    for _, test := range tests {
+        if got := TotalMonths(test.periods); got != test.want {
+            t.Errorf("%s: TotalMonths = %d, want %d", test.name, got, test.want)
+        }
+    }
+}
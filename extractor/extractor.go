@@ -0,0 +1,148 @@
+package extractor
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "cv-extractor/ocr"
+)
+
+var (
+    ErrUnsupportedFormat = errors.New("unsupported CV file format")
+    ErrOCRUnavailable    = errors.New("image CVs need an OCR engine")
+)
+
+// CV file formats recognized from file content.
+const (
+    FormatUnknown = ""
+    FormatPDF     = "pdf"
+    FormatDOCX    = "docx"
+    FormatJPEG    = "jpeg"
+    FormatPNG     = "png"
+    FormatText    = "text"
+)
+
+// DetectFormat recognizes a CV file format from its leading bytes.
+func DetectFormat(data []byte) string {
+    switch {
+    case bytes.HasPrefix(data, []byte("%PDF-")):
+        return FormatPDF
+    case bytes.HasPrefix(data, []byte("PK\x03\x04")) && bytes.Contains(data, []byte("word/document.xml")):
+        // Other ZIP based files, such as spreadsheets, lack the main
+        // document part.
+        return FormatDOCX
+    case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+        return FormatJPEG
+    case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+        return FormatPNG
+    case utf8.Valid(data):
+        return FormatText
+    default:
+        return FormatUnknown
+    }
+}
+
+// IsImage reports whether a format can only be read through OCR.
+func IsImage(format string) bool {
+    return format == FormatJPEG || format == FormatPNG
+}
+
+// minTextLayerLetters is the number of letters below which a PDF is treated
+// as scanned: such PDFs often carry nothing but a page number or a producer
+// stamp in their text layer.
+const minTextLayerLetters = 50
+
+// Extraction is the text of a CV and how it was obtained. OCRPages is only set
+// when the text was recognized by an OCR engine.
+type Extraction struct {
+    Text      string
+    OCREngine string
+    OCRPages  []ocr.Page
+}
+
+// Extract returns the text of a CV file. Images are read with OCR, and so are
+// PDFs without a usable text layer. A nil engine disables OCR.
+func Extract(ctx context.Context, data []byte, engine ocr.Engine) (Extraction, error) {
+    format := DetectFormat(data)
+    if IsImage(format) && engine == nil {
+        return Extraction{}, ErrOCRUnavailable
+    }
+
+    var text string
+    var err error
+    if !IsImage(format) {
+        text, err = ExtractText(data)
+        if format != FormatPDF || engine == nil || (err == nil && countLetters(text) >= minTextLayerLetters) {
+            return Extraction{Text: text}, err
+        }
+    }
+
+    pages, ocrErr := engine.Recognize(ctx, data)
+    if ocrErr != nil {
+        return Extraction{Text: text}, fmt.Errorf("OCR with %s: %v", engine.Name(), ocrErr)
+    }
+    return ocrExtraction(engine, pages), nil
+}
+
+func ocrExtraction(engine ocr.Engine, pages []ocr.Page) Extraction {
+    texts := make([]string, len(pages))
+    for i, page := range pages {
+        texts[i] = page.Text
+    }
+    return Extraction{
+        Text:      normalizeWhitespace(strings.Join(texts, "\n")),
+        OCREngine: engine.Name(),
+        OCRPages:  pages,
+    }
+}
+
+func countLetters(text string) int {
+    n := 0
+    for _, r := range text {
+        if unicode.IsLetter(r) {
+            n++
+        }
+    }
+    return n
+}
+
+// ExtractText returns the text layer of a CV file. The format is detected from
+// the file's content rather than its name, so mislabeled uploads still work.
+// Images have no text layer and are unsupported.
+func ExtractText(data []byte) (string, error) {
+    var text string
+    var err error
+
+    switch DetectFormat(data) {
+    case FormatPDF:
+        text, err = extractPDFText(data)
+    case FormatDOCX:
+        text, err = extractDOCXText(data)
+    case FormatText:
+        text = string(data)
+    default:
+        return "", ErrUnsupportedFormat
+    }
+    if err != nil {
+        return "", err
+    }
+
+    return normalizeWhitespace(text), nil
+}
+
+// normalizeWhitespace collapses runs of spaces within lines and drops blank
+// lines, keeping line breaks since the section parsers rely on them.
+func normalizeWhitespace(text string) string {
+    var lines []string
+    for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+        if line = strings.Join(strings.Fields(line), " "); line != "" {
+            lines = append(lines, line)
+        }
+    }
+    return strings.Join(lines, "\n")
+}
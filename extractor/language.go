@@ -0,0 +1,182 @@
+package extractor
+
+import (
+    "math"
+    "strings"
+    "unicode"
+)
+
+// Languages the structured parser has dictionaries for.
+const (
+    LanguageUnknown    = ""
+    LanguageEnglish    = "en"
+    LanguageIndonesian = "id"
+)
+
+// minLanguageEvidence is the number of stop words a text must contain before
+// a language is attributed to it, and fullLanguageEvidence the number from
+// which the attribution is fully trusted.
+const (
+    minLanguageEvidence  = 5
+    fullLanguageEvidence = 25
+)
+
+// language holds what the parser needs to know about one language.
+type language struct {
+    // stopWords are frequent function words, used both to detect the
+    // language and to drop noise when comparing texts.
+    stopWords map[string]bool
+    // headings maps lowercase section headings to the section they start.
+    headings map[string]string
+    // dayFirst reports whether numeric dates put the day before the month,
+    // as in 31/01/2020.
+    dayFirst bool
+    // searchConfig is the PostgreSQL text search configuration that stems
+    // text in the language.
+    searchConfig string
+}
+
+var languages = map[string]language{
+    LanguageEnglish: {
+        stopWords: wordSet(`a an and are as at be been by for from has have he her his i in is it its my
+            of on or our she that the their this to was we were will with you your`),
+        headings: map[string]string{
+            "work experience":           SectionExperience,
+            "experience":                SectionExperience,
+            "professional experience":   SectionExperience,
+            "employment history":        SectionExperience,
+            "work history":              SectionExperience,
+            "career history":            SectionExperience,
+            "education":                 SectionEducation,
+            "educational background":    SectionEducation,
+            "academic background":       SectionEducation,
+            "skills":                    SectionSkills,
+            "technical skills":          SectionSkills,
+            "certifications":            SectionOther,
+            "projects":                  SectionOther,
+            "languages":                 SectionOther,
+            "references":                SectionOther,
+            "organizational experience": SectionOther,
+            "awards":                    SectionOther,
+            "summary":                   SectionOther,
+            "profile":                   SectionOther,
+            "interests":                 SectionOther,
+        },
+        dayFirst:     false,
+        searchConfig: "english",
+    },
+    LanguageIndonesian: {
+        stopWords: wordSet(`ada adalah akan atau bagi bahwa dalam dan dari dengan di ini itu juga ke
+            kami karena oleh pada para saya sebagai secara serta sudah telah tersebut untuk yang`),
+        headings: map[string]string{
+            "pengalaman kerja":          SectionExperience,
+            "pengalaman bekerja":        SectionExperience,
+            "pengalaman":                SectionExperience,
+            "pengalaman profesional":    SectionExperience,
+            "riwayat pekerjaan":         SectionExperience,
+            "riwayat kerja":             SectionExperience,
+            "pendidikan":                SectionEducation,
+            "riwayat pendidikan":        SectionEducation,
+            "pendidikan formal":         SectionEducation,
+            "latar belakang pendidikan": SectionEducation,
+            "keahlian":                  SectionSkills,
+            "keterampilan":              SectionSkills,
+            "kemampuan":                 SectionSkills,
+            "keahlian teknis":           SectionSkills,
+            "sertifikasi":               SectionOther,
+            "sertifikat":                SectionOther,
+            "pelatihan":                 SectionOther,
+            "proyek":                    SectionOther,
+            "bahasa":                    SectionOther,
+            "referensi":                 SectionOther,
+            "pengalaman organisasi":     SectionOther,
+            "penghargaan":               SectionOther,
+            "prestasi":                  SectionOther,
+            "ringkasan":                 SectionOther,
+            "profil":                    SectionOther,
+            "data diri":                 SectionOther,
+            "data pribadi":              SectionOther,
+            "minat":                     SectionOther,
+        },
+        dayFirst:     true,
+        searchConfig: "indonesian",
+    },
+}
+
+func wordSet(words string) map[string]bool {
+    set := make(map[string]bool)
+    for _, word := range strings.Fields(words) {
+        set[word] = true
+    }
+    return set
+}
+
+// DetectLanguage returns the language whose stop words occur most often in
+// text, or LanguageUnknown when the text is too short or has too few stop
+// words to tell.
+func DetectLanguage(text string) string {
+    language, _ := DetectLanguageConfidence(text)
+    return language
+}
+
+// DetectLanguageConfidence is DetectLanguage with a confidence from 0 to 1
+// that grows with the number of stop words found and with the share of them
+// belonging to the detected language.
+func DetectLanguageConfidence(text string) (string, float64) {
+    counts := make(map[string]int)
+    for _, word := range words(text) {
+        for code, lang := range languages {
+            if lang.stopWords[word] {
+                counts[code]++
+            }
+        }
+    }
+
+    detected, best, total := LanguageUnknown, 0, 0
+    for code, count := range counts {
+        total += count
+        if count > best || (count == best && code < detected) {
+            detected, best = code, count
+        }
+    }
+    if best < minLanguageEvidence {
+        return LanguageUnknown, 0
+    }
+
+    share := float64(best) / float64(total)
+    evidence := math.Min(1, float64(best)/fullLanguageEvidence)
+    return detected, share * evidence
+}
+
+// IsStopWord reports whether a lowercase word is a stop word in any of the
+// supported languages.
+func IsStopWord(word string) bool {
+    for _, lang := range languages {
+        if lang.stopWords[word] {
+            return true
+        }
+    }
+    return false
+}
+
+// IsSupportedLanguage reports whether code is a language the parser has
+// dictionaries for, or LanguageUnknown.
+func IsSupportedLanguage(code string) bool {
+    _, ok := languages[code]
+    return ok || code == LanguageUnknown
+}
+
+// SearchConfig returns the PostgreSQL text search configuration for a
+// language, falling back to "simple", which does no stemming.
+func SearchConfig(code string) string {
+    if lang, ok := languages[code]; ok {
+        return lang.searchConfig
+    }
+    return "simple"
+}
+
+func words(text string) []string {
+    return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r)
+    })
+}
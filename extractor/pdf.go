@@ -0,0 +1,365 @@
+package extractor
+
+import (
+    "bytes"
+    "compress/zlib"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// skippedStreamKeys mark streams that never hold page text: images, fonts and
+// cross-reference or object streams.
+var skippedStreamKeys = []string{"/Image", "/FontFile", "/Length1", "/XRef", "/ObjStm", "/DCTDecode", "/JPXDecode"}
+
+// Flate streams in untrusted PDFs can expand enormously, so each inflated
+// stream is cut at maxInflatedStream bytes and a file's streams stop being
+// inflated once maxInflatedTotal bytes have been produced.
+const (
+    maxInflatedStream = 16 << 20
+    maxInflatedTotal  = 64 << 20
+)
+
+// extractPDFText is a best-effort extractor for the text layer of a PDF. It
+// inflates content streams and collects the strings shown by text operators.
+// Fonts with custom encodings are decoded as Latin-1, or as UTF-16 when the
+// string looks like two-byte glyph codes.
+func extractPDFText(data []byte) (string, error) {
+    var b strings.Builder
+    budget := int64(maxInflatedTotal)
+    for _, stream := range pdfStreams(data) {
+        content := stream.data
+        if bytes.Contains(stream.dict, []byte("/FlateDecode")) {
+            if budget <= 0 {
+                continue
+            }
+            inflated, err := inflate(content, min(budget, maxInflatedStream))
+            if err != nil {
+                continue
+            }
+            budget -= int64(len(inflated))
+            content = inflated
+        } else if bytes.Contains(stream.dict, []byte("/Filter")) {
+            continue
+        }
+
+        if !bytes.Contains(content, []byte("BT")) {
+            continue
+        }
+        parseContentStream(content, &b)
+    }
+    return b.String(), nil
+}
+
+type pdfStream struct {
+    dict []byte
+    data []byte
+}
+
+// pdfStreams returns every stream in the file together with its dictionary,
+// skipping streams that cannot contain page text.
+func pdfStreams(data []byte) []pdfStream {
+    var streams []pdfStream
+    offset := 0
+    for {
+        idx := bytes.Index(data[offset:], []byte("stream"))
+        if idx < 0 {
+            break
+        }
+        start := offset + idx
+        offset = start + len("stream")
+
+        if start >= 3 && string(data[start-3:start]) == "end" {
+            continue
+        }
+
+        dataStart := offset
+        if dataStart < len(data) && data[dataStart] == '\r' {
+            dataStart++
+        }
+        if dataStart < len(data) && data[dataStart] == '\n' {
+            dataStart++
+        }
+
+        dictStart := bytes.LastIndex(data[:start], []byte("obj"))
+        if dictStart < 0 {
+            continue
+        }
+        dict := data[dictStart:start]
+
+        end := bytes.Index(data[dataStart:], []byte("endstream"))
+        if end < 0 {
+            break
+        }
+        offset = dataStart + end + len("endstream")
+
+        skip := false
+        for _, key := range skippedStreamKeys {
+            if bytes.Contains(dict, []byte(key)) {
+                skip = true
+                break
+            }
+        }
+        if !skip {
+            streams = append(streams, pdfStream{dict: dict, data: bytes.TrimRight(data[dataStart:dataStart+end], "\r\n")})
+        }
+    }
+    return streams
+}
+
+// inflate decompresses a zlib stream, keeping at most limit bytes of it.
+func inflate(data []byte, limit int64) ([]byte, error) {
+    r, err := zlib.NewReader(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    defer r.Close()
+
+    inflated, err := io.ReadAll(io.LimitReader(r, limit))
+    if err != nil && len(inflated) == 0 {
+        return nil, err
+    }
+    return inflated, nil
+}
+
+// parseContentStream writes the text shown by the Tj, TJ, ' and " operators,
+// breaking lines on text positioning operators.
+func parseContentStream(content []byte, b *strings.Builder) {
+    var operands []interface{}
+    var array []interface{}
+    inArray := false
+
+    push := func(v interface{}) {
+        if inArray {
+            array = append(array, v)
+        } else {
+            operands = append(operands, v)
+        }
+    }
+
+    for i := 0; i < len(content); {
+        ch := content[i]
+        switch {
+        case ch == '%':
+            for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+                i++
+            }
+        case ch == '(':
+            s, next := readLiteralString(content, i)
+            push(s)
+            i = next
+        case ch == '<' && i+1 < len(content) && content[i+1] == '<':
+            i += 2
+        case ch == '>' && i+1 < len(content) && content[i+1] == '>':
+            i += 2
+        case ch == '<':
+            s, next := readHexString(content, i)
+            push(s)
+            i = next
+        case ch == '[':
+            inArray = true
+            array = nil
+            i++
+        case ch == ']':
+            inArray = false
+            operands = append(operands, array)
+            i++
+        case isPDFWhitespace(ch):
+            i++
+        case ch == '/':
+            start := i
+            i++
+            for i < len(content) && !isPDFWhitespace(content[i]) && !isPDFDelimiter(content[i]) {
+                i++
+            }
+            push(string(content[start:i]))
+        default:
+            start := i
+            for i < len(content) && !isPDFWhitespace(content[i]) && !isPDFDelimiter(content[i]) {
+                i++
+            }
+            if i == start {
+                i++
+                continue
+            }
+            token := string(content[start:i])
+            if n, err := strconv.ParseFloat(token, 64); err == nil {
+                push(n)
+                continue
+            }
+
+            applyTextOperator(token, operands, b)
+            operands = operands[:0]
+        }
+    }
+}
+
+func applyTextOperator(op string, operands []interface{}, b *strings.Builder) {
+    switch op {
+    case "Tj":
+        writeOperandString(operands, b)
+    case "'", "\"":
+        b.WriteString("\n")
+        writeOperandString(operands, b)
+    case "TJ":
+        if len(operands) == 0 {
+            return
+        }
+        items, _ := operands[len(operands)-1].([]interface{})
+        for _, item := range items {
+            switch v := item.(type) {
+            case string:
+                b.WriteString(v)
+            case float64:
+                if v < -200 {
+                    b.WriteString(" ")
+                }
+            }
+        }
+    case "Td", "TD":
+        if len(operands) >= 2 {
+            if ty, ok := operands[1].(float64); ok && ty != 0 {
+                b.WriteString("\n")
+                return
+            }
+        }
+        b.WriteString(" ")
+    case "T*", "ET":
+        b.WriteString("\n")
+    }
+}
+
+func writeOperandString(operands []interface{}, b *strings.Builder) {
+    if len(operands) == 0 {
+        return
+    }
+    if s, ok := operands[len(operands)-1].(string); ok {
+        b.WriteString(s)
+    }
+}
+
+func readLiteralString(content []byte, i int) (string, int) {
+    var raw []byte
+    depth := 0
+    i++
+    for i < len(content) {
+        ch := content[i]
+        switch ch {
+        case '\\':
+            i++
+            if i >= len(content) {
+                break
+            }
+            esc := content[i]
+            switch esc {
+            case 'n':
+                raw = append(raw, '\n')
+            case 'r':
+                raw = append(raw, '\r')
+            case 't':
+                raw = append(raw, '\t')
+            case 'b':
+                raw = append(raw, '\b')
+            case 'f':
+                raw = append(raw, '\f')
+            case '\r', '\n':
+                if esc == '\r' && i+1 < len(content) && content[i+1] == '\n' {
+                    i++
+                }
+            default:
+                if esc >= '0' && esc <= '7' {
+                    n := 0
+                    j := 0
+                    for j < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7' {
+                        n = n*8 + int(content[i]-'0')
+                        i++
+                        j++
+                    }
+                    raw = append(raw, byte(n))
+                    continue
+                }
+                raw = append(raw, esc)
+            }
+            i++
+        case '(':
+            depth++
+            raw = append(raw, ch)
+            i++
+        case ')':
+            if depth == 0 {
+                return decodePDFString(raw), i + 1
+            }
+            depth--
+            raw = append(raw, ch)
+            i++
+        default:
+            raw = append(raw, ch)
+            i++
+        }
+    }
+    return decodePDFString(raw), i
+}
+
+func readHexString(content []byte, i int) (string, int) {
+    end := bytes.IndexByte(content[i:], '>')
+    if end < 0 {
+        return "", len(content)
+    }
+    hex := make([]byte, 0, end)
+    for _, ch := range content[i+1 : i+end] {
+        if !isPDFWhitespace(ch) {
+            hex = append(hex, ch)
+        }
+    }
+    if len(hex)%2 == 1 {
+        hex = append(hex, '0')
+    }
+
+    raw := make([]byte, 0, len(hex)/2)
+    for j := 0; j < len(hex); j += 2 {
+        n, err := strconv.ParseUint(string(hex[j:j+2]), 16, 8)
+        if err != nil {
+            return "", i + end + 1
+        }
+        raw = append(raw, byte(n))
+    }
+    return decodePDFString(raw), i + end + 1
+}
+
+// decodePDFString decodes a shown string. Strings whose every other byte is
+// zero are treated as UTF-16BE, everything else as Latin-1.
+func decodePDFString(raw []byte) string {
+    if bytes.HasPrefix(raw, []byte{0xFE, 0xFF}) {
+        raw = raw[2:]
+    }
+    if len(raw) >= 2 && len(raw)%2 == 0 {
+        utf16 := true
+        for j := 0; j < len(raw); j += 2 {
+            if raw[j] != 0 {
+                utf16 = false
+                break
+            }
+        }
+        if utf16 {
+            runes := make([]rune, 0, len(raw)/2)
+            for j := 0; j < len(raw); j += 2 {
+                runes = append(runes, rune(raw[j+1]))
+            }
+            return string(runes)
+        }
+    }
+
+    runes := make([]rune, len(raw))
+    for j, c := range raw {
+        runes[j] = rune(c)
+    }
+    return string(runes)
+}
+
+func isPDFWhitespace(ch byte) bool {
+    return ch == ' ' || ch == '\n' || ch == '\r' || ch == '\t' || ch == '\f' || ch == 0
+}
+
+func isPDFDelimiter(ch byte) bool {
+    return strings.IndexByte("()<>[]{}/%", ch) >= 0
+}
@@ -0,0 +1,46 @@
+package extractor
+
+import (
+    "strings"
+)
+
+// Section names recognized in CVs.
+const (
+    SectionExperience = "experience"
+    SectionEducation  = "education"
+    SectionSkills     = "skills"
+    SectionOther      = "other"
+)
+
+// Sections splits CV text into its headed sections, recognizing the headings
+// of every supported language since CVs often mix them. Text before the first
+// recognized heading is returned under SectionOther, and a section that
+// appears several times is concatenated.
+func Sections(text string) map[string]string {
+    sections := make(map[string]string)
+    current := SectionOther
+    for _, line := range strings.Split(text, "\n") {
+        if section, ok := headingSection(line); ok {
+            current = section
+            continue
+        }
+        sections[current] += line + "\n"
+    }
+    return sections
+}
+
+// headingSection reports whether a line is a section heading: a short line
+// whose text, ignoring case and trailing punctuation, is a known heading.
+func headingSection(line string) (string, bool) {
+    heading := strings.ToLower(strings.TrimSpace(line))
+    heading = strings.TrimRight(heading, ":.- ")
+    if heading == "" || len(heading) > 40 {
+        return "", false
+    }
+    for _, lang := range languages {
+        if section, ok := lang.headings[heading]; ok {
+            return section, true
+        }
+    }
+    return "", false
+}
@@ -0,0 +1,128 @@
+package filestore
+
+import (
+    "log"
+    "time"
+
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    // deletionBatchSize is how many queued deletions the worker claims at a
+    // time.
+    deletionBatchSize = 50
+
+    // deletionPollInterval is how long the worker waits when the queue is
+    // empty.
+    deletionPollInterval = 30 * time.Second
+
+    // maxRetryDelay caps the backoff between attempts at deleting an object.
+    maxRetryDelay = 6 * time.Hour
+)
+
+// RunDeletionWorker deletes queued objects from storage until the process
+// exits. Failed deletions stay queued and are retried with exponential
+// backoff.
+func RunDeletionWorker(db *gorm.DB) {
+    for {
+        processed, err := ProcessDeletions(db, deletionBatchSize)
+        if err != nil {
+            log.Printf("Failed to process file deletions: %v", err)
+        }
+        if processed < deletionBatchSize {
+            time.Sleep(deletionPollInterval)
+        }
+    }
+}
+
+// ProcessDeletions attempts up to limit queued deletions that are due and
+// returns how many it attempted. Deletions claimed by another worker are
+// skipped.
+func ProcessDeletions(db *gorm.DB, limit int) (int, error) {
+    var due []models.FileDeletion
+    if err := db.Where("next_attempt_date <= ?", time.Now()).
+        Order("next_attempt_date").
+        Limit(limit).
+        Find(&due).Error; err != nil {
+        return 0, err
+    }
+
+    for _, deletion := range due {
+        if err := deleteObject(db, deletion.ID); err != nil {
+            log.Printf("Failed to delete stored object %s (attempt %d): %v", deletion.ObjectName, deletion.Attempts+1, err)
+            if err := scheduleRetry(db, deletion, err); err != nil {
+                return len(due), err
+            }
+        }
+    }
+    return len(due), nil
+}
+
+// deleteObject carries out one queued deletion. An object that was
+// referenced again since it was queued is kept, and only its queue entry is
+// removed.
+func deleteObject(db *gorm.DB, deletionID uint) error {
+    return db.Transaction(func(tx *gorm.DB) error {
+        var deletion models.FileDeletion
+        err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+            First(&deletion, deletionID).Error
+        if err == gorm.ErrRecordNotFound {
+            // Done or claimed by another worker.
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+
+        if err := lockObject(tx, deletion.ObjectName); err != nil {
+            return err
+        }
+
+        var file models.StoredFile
+        err = tx.Where("object_name = ?", deletion.ObjectName).First(&file).Error
+        switch {
+        case err == gorm.ErrRecordNotFound:
+            // An orphan found by reconciliation.
+        case err != nil:
+            return err
+        case file.RefCount > 0:
+            return tx.Delete(&deletion).Error
+        }
+
+        if err := utils.DeleteFileFromFirebase(deletion.ObjectName); err != nil {
+            return err
+        }
+        if file.ID != 0 {
+            if err := tx.Delete(&file).Error; err != nil {
+                return err
+            }
+        }
+        return tx.Delete(&deletion).Error
+    })
+}
+
+// scheduleRetry records a failed attempt and pushes the next one back.
+func scheduleRetry(db *gorm.DB, deletion models.FileDeletion, cause error) error {
+    attempts := deletion.Attempts + 1
+    return db.Model(&deletion).Updates(map[string]interface{}{
+        "attempts":          attempts,
+        "last_error":        cause.Error(),
+        "next_attempt_date": time.Now().Add(retryDelay(attempts)),
+    }).Error
+}
+
+// retryDelay doubles from one minute with each failed attempt, up to
+// maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+    delay := time.Minute
+    for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+        delay *= 2
+    }
+    if delay > maxRetryDelay {
+        delay = maxRetryDelay
+    }
+    return delay
+}
@@ -0,0 +1,143 @@
+// Package filestore keeps uploaded files in storage under names derived from
+// the SHA-256 hash of their content, so a file uploaded many times is stored
+// once. Each file has a reference count in the stored_files table, and its
+// object is queued for deletion in the file_deletions outbox once no CV
+// version refers to it any more.
+package filestore
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "io"
+    "path"
+    "time"
+
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// Store takes a reference to data, uploading it as <dir>/<hash><ext> unless
+// an identical file is already stored. The reference is committed when Store
+// returns; callers that end up not using the file must Release it.
+func Store(db *gorm.DB, dir, ext, contentType string, data []byte) (models.StoredFile, error) {
+    return StoreFile(db, dir, ext, contentType, bytes.NewReader(data))
+}
+
+// StoreFile is Store for a file read from r, which is read twice, once to
+// hash it and once to upload it, so the file is never held in memory.
+func StoreFile(db *gorm.DB, dir, ext, contentType string, r io.ReadSeeker) (models.StoredFile, error) {
+    hasher := sha256.New()
+    size, err := io.Copy(hasher, r)
+    if err != nil {
+        return models.StoredFile{}, err
+    }
+    hash := hex.EncodeToString(hasher.Sum(nil))
+    objectName := path.Join(dir, hash+ext)
+
+    file := models.StoredFile{
+        Hash:        &hash,
+        ObjectName:  objectName,
+        URL:         utils.FileURL(objectName),
+        ContentType: contentType,
+        Size:        size,
+        RefCount:    1,
+    }
+
+    err = db.Transaction(func(tx *gorm.DB) error {
+        // Holding the object's lock keeps the deletion worker from removing
+        // it while it is being reused.
+        if err := lockObject(tx, objectName); err != nil {
+            return err
+        }
+        if err := tx.Clauses(clause.OnConflict{
+            Columns: []clause.Column{{Name: "hash"}},
+            DoUpdates: clause.Assignments(map[string]interface{}{
+                "ref_count":    gorm.Expr("stored_files.ref_count + 1"),
+                "updated_date": time.Now(),
+            }),
+        }).Create(&file).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("hash = ?", hash).First(&file).Error; err != nil {
+            return err
+        }
+        if file.RefCount > 1 {
+            return nil
+        }
+
+        // First reference: either a new file, or one whose last reference
+        // was released but which has not been deleted yet. Uploading again is
+        // harmless in the latter case since the content is the same.
+        if _, err := r.Seek(0, io.SeekStart); err != nil {
+            return err
+        }
+        _, err := utils.UploadToFirebase(file.ObjectName, file.ContentType, r)
+        return err
+    })
+    if err != nil {
+        return models.StoredFile{}, err
+    }
+    return file, nil
+}
+
+// Retain takes one more reference to the file behind each URL, as part of
+// the caller's transaction, for a new row that shares an existing file.
+// Empty and unknown URLs are ignored.
+func Retain(tx *gorm.DB, urls ...string) error {
+    for _, url := range urls {
+        if url == "" {
+            continue
+        }
+        if err := tx.Model(&models.StoredFile{}).
+            Where("url = ?", url).
+            Update("ref_count", gorm.Expr("ref_count + 1")).Error; err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Release drops one reference to the file behind each URL, as part of the
+// caller's transaction. Files left without references are queued for
+// deletion in the same transaction, so their objects are removed by the
+// deletion worker once it commits. Empty and unknown URLs are ignored.
+func Release(tx *gorm.DB, urls ...string) error {
+    for _, url := range urls {
+        if url == "" {
+            continue
+        }
+
+        var file models.StoredFile
+        result := tx.Model(&file).
+            Clauses(clause.Returning{}).
+            Where("url = ? AND ref_count > 0", url).
+            Update("ref_count", gorm.Expr("ref_count - 1"))
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected > 0 && file.RefCount == 0 {
+            if err := enqueueDeletion(tx, file.ObjectName); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// enqueueDeletion records that an object should be deleted from storage. An
+// object already waiting for deletion keeps its place in the queue.
+func enqueueDeletion(tx *gorm.DB, objectName string) error {
+    return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.FileDeletion{
+        ObjectName:      objectName,
+        NextAttemptDate: time.Now(),
+    }).Error
+}
+
+// lockObject serializes work on one object across transactions, covering
+// objects that have no stored_files row to lock.
+func lockObject(tx *gorm.DB, objectName string) error {
+    return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", objectName).Error
+}
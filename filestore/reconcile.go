@@ -0,0 +1,100 @@
+package filestore
+
+import (
+    "log"
+    "time"
+
+    "cv-extractor/models"
+    "cv-extractor/utils"
+    "gorm.io/gorm"
+)
+
+// managedPrefixes are the bucket folders whose objects are tracked in
+// stored_files.
+var managedPrefixes = []string{"cv_files/", "thumbnails/"}
+
+const (
+    // orphanGracePeriod is how old an untracked object must be before it is
+    // treated as an orphan. Store uploads an object before committing its
+    // row, so a newer object may belong to an upload still in progress.
+    orphanGracePeriod = 24 * time.Hour
+
+    // reconcileInterval is how often the server reconciles storage.
+    reconcileInterval = 24 * time.Hour
+)
+
+// ReconcileResult counts what a reconciliation found.
+type ReconcileResult struct {
+    Scanned      int `json:"scanned"`
+    Orphaned     int `json:"orphaned"`
+    Unreferenced int `json:"unreferenced"`
+}
+
+// RunReconciler reconciles storage once a day until the process exits.
+func RunReconciler(db *gorm.DB) {
+    for {
+        result, err := Reconcile(db, false)
+        if err != nil {
+            log.Printf("Failed to reconcile storage: %v", err)
+        } else {
+            log.Printf("Reconciled storage: %d objects scanned, %d orphaned, %d unreferenced", result.Scanned, result.Orphaned, result.Unreferenced)
+        }
+        time.Sleep(reconcileInterval)
+    }
+}
+
+// Reconcile queues for deletion the bucket objects no stored_files row
+// tracks, such as those left behind by uploads that failed half-way, and
+// the files left without references but missing from the queue. With dryRun
+// it only counts them.
+func Reconcile(db *gorm.DB, dryRun bool) (ReconcileResult, error) {
+    var result ReconcileResult
+
+    var tracked []string
+    if err := db.Model(&models.StoredFile{}).Pluck("object_name", &tracked).Error; err != nil {
+        return result, err
+    }
+    known := make(map[string]bool, len(tracked))
+    for _, name := range tracked {
+        known[name] = true
+    }
+
+    cutoff := time.Now().Add(-orphanGracePeriod)
+    for _, prefix := range managedPrefixes {
+        objects, err := utils.ListFirebaseObjects(prefix)
+        if err != nil {
+            return result, err
+        }
+
+        for _, object := range objects {
+            result.Scanned++
+            if known[object.Name] || object.Updated.After(cutoff) {
+                continue
+            }
+            result.Orphaned++
+            if !dryRun {
+                if err := enqueueDeletion(db, object.Name); err != nil {
+                    return result, err
+                }
+            }
+        }
+    }
+
+    var unreferenced []string
+    if err := db.Model(&models.StoredFile{}).
+        Where("ref_count = 0").
+        Where("NOT EXISTS (SELECT 1 FROM file_deletions WHERE file_deletions.object_name = stored_files.object_name)").
+        Pluck("object_name", &unreferenced).Error; err != nil {
+        return result, err
+    }
+    result.Unreferenced = len(unreferenced)
+    if !dryRun {
+        for _, name := range unreferenced {
+            if err := enqueueDeletion(db, name); err != nil {
+                return result, err
+            }
+        }
+    }
+
+    return result, nil
+}
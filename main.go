@@ -2,6 +2,8 @@ package main
 
 import (
     "cv-extractor/config"
+    "cv-extractor/controller"
+    "cv-extractor/filestore"
     "cv-extractor/routes"
     "cv-extractor/utils"
     "log"
@@ -9,11 +11,27 @@ import (
 )
 
 func main() {
+    if len(os.Args) > 1 && os.Args[1] == "reextract" {
+        reextract(os.Args[2:])
+        return
+    }
+    if len(os.Args) > 1 && os.Args[1] == "reconcile-storage" {
+        reconcileStorage(os.Args[2:])
+        return
+    }
+
     config.InitDB()
     if err := utils.InitFirebase(); err != nil {
         log.Fatalf("Failed to initialize Firebase: %v", err)
     }
 
+    go controller.RunExtractionJobResumer()
+    go filestore.RunDeletionWorker(config.DB)
+    go filestore.RunReconciler(config.DB)
+    go controller.RunTrashPurger()
+    go controller.RunPositionCloser()
+    go controller.RunEmbeddingBackfill()
+
     r := routes.SetupRouter()
 
     port := os.Getenv("PORT")
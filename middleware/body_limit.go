@@ -0,0 +1,17 @@
+package middleware
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+// BodyLimit caps the size of request bodies. Reading past the limit fails,
+// so oversized uploads are cut off while they stream in instead of being
+// spooled to memory or disk first.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+        c.Next()
+    }
+}
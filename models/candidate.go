@@ -2,19 +2,65 @@ package models
 
 import (
     "time"
+
+    "gorm.io/gorm"
 )
 
 type Candidate struct {
-    ID          uint      `gorm:"primaryKey"`
-    CVFile      string    `gorm:"size:255"`
-    CVFileURL   string    `gorm:"size:255"`
-    Name        string    `gorm:"size:255;not null"`
-    Email       string    `gorm:"size:255;not null"`
-    Domicile    string    `gorm:"size:255"`
-    Score       float64   `gorm:"type:float"`
-    Skills      string    `gorm:"type:text"`
-    IsQualified bool      `gorm:"default:false"`
-    PositionID  uint      `gorm:"not null"`
-    Position    Position  `gorm:"foreignKey:PositionID"`
-    CreatedDate time.Time `gorm:"autoCreateTime"`
+    ID                   uint                       `gorm:"primaryKey"`
+    CVFile               string                     `gorm:"size:255"`
+    ThumbnailURL         string                     `gorm:"size:255"`
+    CVVersions           []CandidateCVVersion       `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    CVVersion            int                        `gorm:"not null;default:1"`
+    CVFileURL            string                     `gorm:"size:255"`
+    Name                 string                     `gorm:"size:255;not null"`
+    Email                string                     `gorm:"size:255;not null"`
+    Phone                string                     `gorm:"size:64"`
+    Domicile             string                     `gorm:"size:255"`
+    Score                float64                    `gorm:"type:float"`
+    ScoredDate           *time.Time                 `gorm:"index"`
+    ScoreManual          bool                       `gorm:"not null;default:false"`
+    Skills               string                     `gorm:"type:text"`
+    SkillsManual         bool                       `gorm:"not null;default:false"`
+    CVText               string                     `gorm:"type:text" json:"-"`
+    CVTextConfig         string                     `gorm:"size:32;default:english" json:"-"`
+    FromOCR              bool                       `gorm:"default:false;index"`
+    OCRPages             []CandidateOCRPage         `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    Language             string                     `gorm:"size:8;index"`
+    IsQualified          bool                       `gorm:"default:false"`
+    EducationLevel       EducationLevel             `gorm:"default:0;index"`
+    ExperienceMonths     int                        `gorm:"default:0"`
+    SkillExperience      []CandidateSkillExperience `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    ExtractionVersion    int                        `gorm:"default:0;index"`
+    ExtractionConfidence float64                    `gorm:"default:0"`
+    FieldExtractions     []CandidateFieldExtraction `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    FieldReviews         []CandidateFieldReview     `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    ReviewStatus         string                     `gorm:"size:16;not null;default:not_required;index"`
+    Stage                string                     `gorm:"size:32;not null;default:applied;index"`
+    Source               string                     `gorm:"size:64;index"`
+    PositionID           uint                       `gorm:"not null;index"`
+    Position             Position                   `gorm:"foreignKey:PositionID"`
+    ProfileID            *uint                      `gorm:"index"`
+    Profile              *Profile                   `gorm:"foreignKey:ProfileID"`
+    Tags                 []Tag                      `gorm:"many2many:candidate_tags;constraint:OnDelete:CASCADE;"`
+    Notes                []CandidateNote            `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    StageHistory         []CandidateStageHistory    `gorm:"foreignKey:CandidateID;constraint:OnDelete:CASCADE;"`
+    CreatedDate          time.Time                  `gorm:"autoCreateTime"`
+    DeletedAt            gorm.DeletedAt             `gorm:"index"`
+}
+
+// SetScore records a new score for the candidate. ScoredDate tells scored
+// candidates apart from unscored ones, since a score of 0 is legitimate.
+func (c *Candidate) SetScore(score float64, now time.Time) {
+    c.Score = score
+    c.ScoredDate = &now
+}
+
+// SetManualScore records a score and skills a recruiter entered by hand.
+// Re-extraction keeps them until a new CV version is uploaded.
+func (c *Candidate) SetManualScore(score float64, skills string, now time.Time) {
+    c.SetScore(score, now)
+    c.ScoreManual = true
+    c.Skills = skills
+    c.SkillsManual = true
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "time"
+)
+
+// CandidateCVVersion is one CV a candidate has sent, with the values the
+// parser extracted from it. Files of earlier versions stay in storage so they
+// can still be downloaded after a replacement; each version holds one
+// reference to its CV file and one to its thumbnail in stored_files.
+type CandidateCVVersion struct {
+    ID           uint        `gorm:"primaryKey"`
+    CandidateID  uint        `gorm:"not null;uniqueIndex:idx_candidate_cv_versions_version"`
+    Version      int         `gorm:"not null;uniqueIndex:idx_candidate_cv_versions_version"`
+    CVFile       string      `gorm:"size:255"`
+    ThumbnailURL string      `gorm:"size:255"`
+    CVText       string      `gorm:"type:text" json:"-"`
+    Fields       FieldValues `gorm:"type:text"`
+    UserID       *uint       `gorm:"index"`
+    User         *User       `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+    CreatedDate  time.Time   `gorm:"autoCreateTime"`
+}
+
+// FieldValues maps extracted field names to their values, stored as JSON.
+type FieldValues map[string]string
+
+func (f FieldValues) Value() (driver.Value, error) {
+    data, err := json.Marshal(f)
+    if err != nil {
+        return nil, err
+    }
+    return string(data), nil
+}
+
+func (f *FieldValues) Scan(value interface{}) error {
+    var data []byte
+    switch v := value.(type) {
+    case nil:
+        *f = nil
+        return nil
+    case string:
+        data = []byte(v)
+    case []byte:
+        data = v
+    default:
+        return fmt.Errorf("cannot scan %T into FieldValues", value)
+    }
+    return json.Unmarshal(data, f)
+}
@@ -2,13 +2,16 @@ package models
 
 import (
     "time"
+
+    "gorm.io/gorm"
 )
 
 type Department struct {
-    ID          uint       `gorm:"primaryKey"`
-    Name        string     `gorm:"size:255;not null"`
-    CompanyID   uint       `gorm:"not null"`
-    Company     Company    `gorm:"foreignKey:CompanyID"`
-    Positions   []Position `gorm:"foreignKey:DepartmentID"`
-    CreatedDate time.Time  `gorm:"autoCreateTime"`
+    ID          uint           `gorm:"primaryKey"`
+    Name        string         `gorm:"size:255;not null"`
+    CompanyID   uint           `gorm:"not null"`
+    Company     Company        `gorm:"foreignKey:CompanyID"`
+    Positions   []Position     `gorm:"foreignKey:DepartmentID"`
+    CreatedDate time.Time      `gorm:"autoCreateTime"`
+    DeletedAt   gorm.DeletedAt `gorm:"index"`
 }
@@ -0,0 +1,137 @@
+package models
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    "cv-extractor/utils"
+)
+
+// EducationLevel is an ordered degree level, so requirements can be compared
+// with "at least" semantics. The zero value means the level is unknown.
+type EducationLevel int
+
+const (
+    EducationUnknown EducationLevel = iota
+    EducationHighSchool
+    EducationDiploma
+    EducationBachelor
+    EducationMaster
+    EducationDoctorate
+)
+
+var educationLevelNames = map[EducationLevel]string{
+    EducationUnknown:    "unknown",
+    EducationHighSchool: "high_school",
+    EducationDiploma:    "diploma",
+    EducationBachelor:   "bachelor",
+    EducationMaster:     "master",
+    EducationDoctorate:  "doctorate",
+}
+
+// educationAliases lists the ways each level is written in English and
+// Indonesian CVs and job ads. Indonesian D4 (Sarjana Terapan) is equivalent
+// to a bachelor's degree.
+var educationAliases = map[EducationLevel][]string{
+    EducationHighSchool: {
+        "high school", "secondary school", "senior high school", "sma", "smk", "slta", "stm",
+        "sekolah menengah atas", "sekolah menengah kejuruan", "madrasah aliyah",
+    },
+    EducationDiploma: {
+        "diploma", "associate degree", "d1", "d2", "d3", "d-3", "ahli madya", "a.md",
+    },
+    EducationBachelor: {
+        "bachelor", "bachelors", "bachelor degree", "undergraduate", "s1", "s-1", "sarjana", "d4", "d-4",
+        "sarjana terapan", "b.sc", "bsc", "b.a", "b.eng", "beng", "b.s", "s.kom", "s.t", "s.e", "s.h", "licenciatura",
+    },
+    EducationMaster: {
+        "master", "masters", "master degree", "postgraduate", "s2", "s-2", "magister", "m.sc", "msc", "m.a",
+        "mba", "m.eng", "meng", "m.kom", "m.t", "m.m",
+    },
+    EducationDoctorate: {
+        "doctorate", "doctoral", "doctor of philosophy", "phd", "ph.d", "s3", "s-3", "doktor", "dr.rer.nat",
+    },
+}
+
+func (l EducationLevel) String() string {
+    if name, ok := educationLevelNames[l]; ok {
+        return name
+    }
+    return educationLevelNames[EducationUnknown]
+}
+
+// AtLeast reports whether l meets a minimum level. Any level meets an unknown
+// requirement, while an unknown level meets no known requirement.
+func (l EducationLevel) AtLeast(min EducationLevel) bool {
+    return min == EducationUnknown || l >= min
+}
+
+func (l EducationLevel) MarshalJSON() ([]byte, error) {
+    return json.Marshal(l.String())
+}
+
+// UnmarshalJSON accepts a level name or any alias, such as "S1" or
+// "Bachelor", as well as the numeric level.
+func (l *EducationLevel) UnmarshalJSON(data []byte) error {
+    var number int
+    if err := json.Unmarshal(data, &number); err == nil {
+        if _, ok := educationLevelNames[EducationLevel(number)]; !ok {
+            return fmt.Errorf("unknown education level %d", number)
+        }
+        *l = EducationLevel(number)
+        return nil
+    }
+
+    var text string
+    if err := json.Unmarshal(data, &text); err != nil {
+        return err
+    }
+    if strings.TrimSpace(text) == "" {
+        *l = EducationUnknown
+        return nil
+    }
+    level := ParseEducationRequirement(text)
+    if level == EducationUnknown {
+        return fmt.Errorf("unknown education level %q", text)
+    }
+    *l = level
+    return nil
+}
+
+// ParseEducationLevel returns the highest education level mentioned in text
+// as whole words, which is the degree a CV's education section proves. It
+// returns EducationUnknown when nothing matches.
+func ParseEducationLevel(text string) EducationLevel {
+    levels := educationLevelsIn(text)
+    if len(levels) == 0 {
+        return EducationUnknown
+    }
+    return levels[len(levels)-1]
+}
+
+// ParseEducationRequirement returns the lowest education level mentioned in
+// text, so a position asking for "S1/S2" accepts a bachelor's degree.
+func ParseEducationRequirement(text string) EducationLevel {
+    levels := educationLevelsIn(text)
+    if len(levels) == 0 {
+        return EducationUnknown
+    }
+    return levels[0]
+}
+
+// educationLevelsIn returns the levels mentioned in text in ascending order.
+func educationLevelsIn(text string) []EducationLevel {
+    key := utils.PhraseKey(text)
+
+    var levels []EducationLevel
+    for level := EducationHighSchool; level <= EducationDoctorate; level++ {
+        for _, alias := range educationAliases[level] {
+            if strings.Contains(key, utils.PhraseKey(alias)) {
+                levels = append(levels, level)
+                break
+            }
+        }
+    }
+    return levels
+}
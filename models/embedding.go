@@ -0,0 +1,53 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/binary"
+    "fmt"
+    "math"
+    "time"
+)
+
+const (
+    EmbeddingOwnerCandidate = "candidate"
+    EmbeddingOwnerPosition  = "position"
+)
+
+// Embedding stores the vector a provider computed for a candidate's CV or a
+// position's requirements.
+type Embedding struct {
+    ID          uint      `gorm:"primaryKey"`
+    OwnerType   string    `gorm:"size:32;not null;uniqueIndex:idx_embeddings_owner"`
+    OwnerID     uint      `gorm:"not null;uniqueIndex:idx_embeddings_owner"`
+    Provider    string    `gorm:"size:64;not null;uniqueIndex:idx_embeddings_owner"`
+    Vector      Vector    `gorm:"type:bytea;not null"`
+    UpdatedDate time.Time `gorm:"autoUpdateTime"`
+}
+
+// Vector is stored as little-endian float32 values.
+type Vector []float32
+
+func (v Vector) Value() (driver.Value, error) {
+    data := make([]byte, 4*len(v))
+    for i, f := range v {
+        binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(f))
+    }
+    return data, nil
+}
+
+func (v *Vector) Scan(value interface{}) error {
+    data, ok := value.([]byte)
+    if !ok {
+        return fmt.Errorf("cannot scan %T into Vector", value)
+    }
+    if len(data)%4 != 0 {
+        return fmt.Errorf("invalid vector length %d", len(data))
+    }
+
+    vector := make(Vector, len(data)/4)
+    for i := range vector {
+        vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+    }
+    *v = vector
+    return nil
+}
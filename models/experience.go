@@ -0,0 +1,10 @@
+package models
+
+// CandidateSkillExperience is the number of months a candidate's work history
+// shows them using a skill, with overlapping jobs counted once.
+type CandidateSkillExperience struct {
+    ID          uint   `gorm:"primaryKey"`
+    CandidateID uint   `gorm:"not null;index"`
+    Skill       string `gorm:"size:255;not null;index"`
+    Months      int    `gorm:"not null"`
+}
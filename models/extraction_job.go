@@ -0,0 +1,37 @@
+package models
+
+import (
+    "time"
+)
+
+// Extraction job states.
+const (
+    JobQueued    = "queued"
+    JobRunning   = "running"
+    JobCompleted = "completed"
+    JobFailed    = "failed"
+)
+
+// ExtractionJob re-runs CV extraction and scoring for the candidates of a
+// company, optionally narrowed to one position and to candidates processed by
+// a range of extraction versions.
+type ExtractionJob struct {
+    ID            uint      `gorm:"primaryKey"`
+    CompanyID     uint      `gorm:"not null;index"`
+    Company       Company   `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;"`
+    PositionID    *uint     `gorm:"index"`
+    Position      *Position `gorm:"foreignKey:PositionID;constraint:OnDelete:CASCADE;"`
+    MinVersion    *int
+    MaxVersion    *int
+    Status        string    `gorm:"size:16;not null;default:queued;index"`
+    Total         int       `gorm:"default:0"`
+    Processed     int       `gorm:"default:0"`
+    Failed        int       `gorm:"default:0"`
+    Error         string    `gorm:"type:text"`
+    UserID        *uint     `gorm:"index"`
+    User          *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+    CreatedDate   time.Time `gorm:"autoCreateTime"`
+    StartedDate   *time.Time
+    HeartbeatDate *time.Time
+    FinishedDate  *time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+    "time"
+)
+
+// FileDeletion is a storage object waiting to be deleted. Rows are written in
+// the same transaction that drops the object's last reference, and a
+// background worker deletes the object, retrying with backoff on failure.
+type FileDeletion struct {
+    ID              uint      `gorm:"primaryKey"`
+    ObjectName      string    `gorm:"size:255;not null;uniqueIndex"`
+    Attempts        int       `gorm:"not null;default:0"`
+    LastError       string    `gorm:"type:text"`
+    NextAttemptDate time.Time `gorm:"not null;index"`
+    CreatedDate     time.Time `gorm:"autoCreateTime"`
+}
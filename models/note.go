@@ -0,0 +1,14 @@
+package models
+
+import (
+    "time"
+)
+
+type CandidateNote struct {
+    ID          uint      `gorm:"primaryKey"`
+    CandidateID uint      `gorm:"not null;index"`
+    UserID      *uint     `gorm:"index"`
+    User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+    Body        string    `gorm:"type:text;not null"`
+    CreatedDate time.Time `gorm:"autoCreateTime"`
+}
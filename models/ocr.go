@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+)
+
+// CandidateOCRPage records how confident the OCR engine was about each page
+// of a CV whose text was recognized from a scan.
+type CandidateOCRPage struct {
+    ID          uint      `gorm:"primaryKey"`
+    CandidateID uint      `gorm:"not null;index;uniqueIndex:idx_candidate_ocr_pages_page"`
+    PageNumber  int       `gorm:"not null;uniqueIndex:idx_candidate_ocr_pages_page"`
+    Engine      string    `gorm:"size:32;not null"`
+    Confidence  float64   `gorm:"not null"`
+    CreatedDate time.Time `gorm:"autoCreateTime"`
+}
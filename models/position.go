@@ -1,22 +1,33 @@
 package models
 
-import "time"
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
 
 type Position struct {
-    ID                  uint       `gorm:"primaryKey"`
-    Name                string     `gorm:"not null"`
-    Education           string     `gorm:"not null"`
-    Location            string     `gorm:"not null"`
-    MinWorkExp          int        `gorm:"not null"`
-    Description         string     `gorm:"not null"`
-    Qualification       string     `gorm:"not null"`
-    DepartmentID        uint       `gorm:"not null"`
-    Department          Department `gorm:"foreignKey:DepartmentID"` // Ensure this relationship is defined
-    CreatedDate         time.Time  `gorm:"autoCreateTime"`
-    IsResolved          bool       `gorm:"default:false"`
-    IsTrash             bool       `gorm:"default:false"`
-    IsArchive          bool       `gorm:"default:false"`
-    RemovedDate         time.Time  `gorm:"autoUpdateTime"`
-    QualifiedCandidates string     `gorm:"type:text"`
-    UploadedCV          int        `gorm:"default:0"`
+    ID                  uint                    `gorm:"primaryKey"`
+    Name                string                  `gorm:"not null"`
+    Education           string                  `gorm:"not null"`
+    EducationLevel      EducationLevel          `gorm:"default:0;index"`
+    Location            string                  `gorm:"not null"`
+    MinWorkExp          int                     `gorm:"not null"`
+    Description         string                  `gorm:"not null"`
+    Qualification       string                  `gorm:"not null"`
+    DepartmentID        uint                    `gorm:"not null"`
+    Department          Department              `gorm:"foreignKey:DepartmentID"` // Ensure this relationship is defined
+    CreatedDate         time.Time               `gorm:"autoCreateTime"`
+    Status              string                  `gorm:"size:16;not null;default:open;index"`
+    StatusDate          time.Time               `gorm:"autoCreateTime"`
+    StatusHistory       []PositionStatusHistory `gorm:"foreignKey:PositionID;constraint:OnDelete:CASCADE;"`
+    Headcount           int                     `gorm:"not null;default:1"`
+    ApplicationDeadline *time.Time              `gorm:"index"`
+    SalaryMin           *int64
+    SalaryMax           *int64
+    SalaryCurrency      string                  `gorm:"size:3"`
+    EmploymentType      string                  `gorm:"size:16;index"`
+    HiringTeam          []PositionTeamMember    `gorm:"foreignKey:PositionID;constraint:OnDelete:CASCADE;"`
+    QualifiedCandidates string                  `gorm:"type:text"`
+    DeletedAt           gorm.DeletedAt          `gorm:"index"`
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+    "time"
+)
+
+// Employment types a position can be advertised with.
+const (
+    EmploymentFullTime   = "full_time"
+    EmploymentPartTime   = "part_time"
+    EmploymentContract   = "contract"
+    EmploymentInternship = "internship"
+    EmploymentTemporary  = "temporary"
+)
+
+var EmploymentTypes = []string{EmploymentFullTime, EmploymentPartTime, EmploymentContract, EmploymentInternship, EmploymentTemporary}
+
+// Roles of the users on a position's hiring team. A position has at most one
+// recruiter and one hiring manager, and any number of interviewers.
+const (
+    TeamRecruiter     = "recruiter"
+    TeamHiringManager = "hiring_manager"
+    TeamInterviewer   = "interviewer"
+)
+
+// PositionTeamMember assigns a company user to a position's hiring team.
+type PositionTeamMember struct {
+    ID          uint      `gorm:"primaryKey"`
+    PositionID  uint      `gorm:"not null;uniqueIndex:idx_position_team_member"`
+    UserID      uint      `gorm:"not null;uniqueIndex:idx_position_team_member;index"`
+    User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+    Role        string    `gorm:"size:16;not null;uniqueIndex:idx_position_team_member"`
+    CreatedDate time.Time `gorm:"autoCreateTime"`
+}
+
+// AcceptsApplications reports whether candidates may still apply for the
+// position: it is open and its application deadline, if any, has not
+// passed.
+func (p Position) AcceptsApplications(now time.Time) bool {
+    if p.Status != PositionOpen {
+        return false
+    }
+    return p.ApplicationDeadline == nil || now.Before(*p.ApplicationDeadline)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+    "time"
+)
+
+// Lifecycle statuses of a position.
+const (
+    PositionDraft    = "draft"
+    PositionOpen     = "open"
+    PositionOnHold   = "on_hold"
+    PositionFilled   = "filled"
+    PositionClosed   = "closed"
+    PositionArchived = "archived"
+)
+
+var PositionStatuses = []string{PositionDraft, PositionOpen, PositionOnHold, PositionFilled, PositionClosed, PositionArchived}
+
+// positionTransitions lists the statuses a position may move to from each
+// status. Filled and closed positions can be reopened, and an archived one
+// only returns to the status it was archived from.
+var positionTransitions = map[string][]string{
+    PositionDraft:    {PositionOpen, PositionClosed},
+    PositionOpen:     {PositionOnHold, PositionFilled, PositionClosed},
+    PositionOnHold:   {PositionOpen, PositionFilled, PositionClosed},
+    PositionFilled:   {PositionOpen, PositionClosed, PositionArchived},
+    PositionClosed:   {PositionOpen, PositionArchived},
+    PositionArchived: {PositionFilled, PositionClosed},
+}
+
+func IsValidPositionStatus(status string) bool {
+    _, ok := positionTransitions[status]
+    return ok
+}
+
+// PositionTransitions returns the statuses a position in status may move to.
+func PositionTransitions(status string) []string {
+    return positionTransitions[status]
+}
+
+// CanTransitionPosition reports whether a position may move from one status
+// to another.
+func CanTransitionPosition(from, to string) bool {
+    for _, status := range positionTransitions[from] {
+        if status == to {
+            return true
+        }
+    }
+    return false
+}
+
+// PositionStatusHistory records every status change of a position.
+type PositionStatusHistory struct {
+    ID          uint      `gorm:"primaryKey"`
+    PositionID  uint      `gorm:"not null;index"`
+    FromStatus  string    `gorm:"size:16"`
+    ToStatus    string    `gorm:"size:16;not null"`
+    UserID      *uint     `gorm:"index"`
+    User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+    ChangedDate time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+    "time"
+)
+
+// Profile is a person known to a company. Every candidate row is one
+// application of a profile to a position.
+type Profile struct {
+    ID             uint        `gorm:"primaryKey"`
+    Name           string      `gorm:"size:255;not null"`
+    NormalizedName string      `gorm:"size:255;index"`
+    Email          string      `gorm:"size:255;index"`
+    Phone          string      `gorm:"size:64;index"`
+    CompanyID      uint        `gorm:"not null;index"`
+    Company        Company     `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;"`
+    Candidates     []Candidate `gorm:"foreignKey:ProfileID;constraint:OnDelete:SET NULL;"`
+    CreatedDate    time.Time   `gorm:"autoCreateTime"`
+}
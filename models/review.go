@@ -0,0 +1,91 @@
+package models
+
+import (
+    "strconv"
+    "time"
+)
+
+// Review states of a candidate's extracted data.
+const (
+    ReviewNotRequired = "not_required"
+    ReviewPending     = "pending"
+    ReviewReviewed    = "reviewed"
+)
+
+// Candidate fields filled by CV extraction that recruiters can review.
+const (
+    FieldLanguage         = "language"
+    FieldSkills           = "skills"
+    FieldEducationLevel   = "educationLevel"
+    FieldExperienceMonths = "experienceMonths"
+)
+
+var ExtractedFields = []string{FieldLanguage, FieldSkills, FieldEducationLevel, FieldExperienceMonths}
+
+func IsExtractedField(field string) bool {
+    for _, f := range ExtractedFields {
+        if f == field {
+            return true
+        }
+    }
+    return false
+}
+
+// FieldValue returns the value of an extracted field in the form stored in
+// field extractions, reviews and CV versions.
+func (c Candidate) FieldValue(field string) string {
+    switch field {
+    case FieldLanguage:
+        return c.Language
+    case FieldSkills:
+        return c.Skills
+    case FieldEducationLevel:
+        return c.EducationLevel.String()
+    case FieldExperienceMonths:
+        return strconv.Itoa(c.ExperienceMonths)
+    default:
+        return ""
+    }
+}
+
+// FieldValues returns the values of all extracted fields.
+func (c Candidate) FieldValues() FieldValues {
+    values := make(FieldValues, len(ExtractedFields))
+    for _, field := range ExtractedFields {
+        values[field] = c.FieldValue(field)
+    }
+    return values
+}
+
+// CandidateFieldExtraction is the raw value the parser extracted for one
+// field of a candidate, with its confidence from 0 to 1. It is kept as the
+// parser produced it even after a recruiter corrects the candidate.
+type CandidateFieldExtraction struct {
+    ID          uint      `gorm:"primaryKey"`
+    CandidateID uint      `gorm:"not null;uniqueIndex:idx_candidate_field_extractions_field"`
+    Field       string    `gorm:"size:32;not null;uniqueIndex:idx_candidate_field_extractions_field"`
+    Value       string    `gorm:"type:text"`
+    Confidence  float64   `gorm:"not null"`
+    CreatedDate time.Time `gorm:"autoCreateTime"`
+}
+
+// CandidateFieldReview records a recruiter's verdict on an extracted field:
+// either the extracted value was accepted or it was corrected to
+// ReviewedValue. Comparing the two measures the parser's accuracy. CVVersion
+// is the candidate's CV version the review applies to, and ExtractionVersion
+// and ExtractedConfidence record the parser run that produced the reviewed
+// value, so accuracy can be compared across parser versions.
+type CandidateFieldReview struct {
+    ID                  uint      `gorm:"primaryKey"`
+    CandidateID         uint      `gorm:"not null;index"`
+    Field               string    `gorm:"size:32;not null;index"`
+    ExtractedValue      string    `gorm:"type:text"`
+    ReviewedValue       string    `gorm:"type:text"`
+    Corrected           bool      `gorm:"not null;default:false"`
+    CVVersion           int       `gorm:"not null;default:1"`
+    ExtractionVersion   int       `gorm:"not null;default:0;index"`
+    ExtractedConfidence float64   `gorm:"not null;default:0"`
+    UserID              *uint     `gorm:"index"`
+    User                *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+    ReviewedDate        time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+    "time"
+)
+
+// Skill is a canonical skill in a company's taxonomy. Aliases are the other
+// spellings that normalize to it, and Parent groups related skills, such as
+// "React" under "JavaScript".
+type Skill struct {
+    ID             uint         `gorm:"primaryKey"`
+    Name           string       `gorm:"size:255;not null"`
+    NormalizedName string       `gorm:"size:255;not null;uniqueIndex:idx_skills_company_name"`
+    Category       string       `gorm:"size:255"`
+    ParentID       *uint        `gorm:"index"`
+    Parent         *Skill       `gorm:"foreignKey:ParentID"`
+    Children       []Skill      `gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL;"`
+    Aliases        []SkillAlias `gorm:"foreignKey:SkillID;constraint:OnDelete:CASCADE;"`
+    CompanyID      uint         `gorm:"not null;uniqueIndex:idx_skills_company_name"`
+    Company        Company      `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;"`
+    CreatedDate    time.Time    `gorm:"autoCreateTime"`
+}
+
+type SkillAlias struct {
+    ID        uint   `gorm:"primaryKey"`
+    SkillID   uint   `gorm:"not null;index"`
+    Alias     string `gorm:"size:255;not null;uniqueIndex:idx_skill_aliases_company_alias"`
+    CompanyID uint   `gorm:"not null;uniqueIndex:idx_skill_aliases_company_alias"`
+}
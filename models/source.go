@@ -0,0 +1,13 @@
+package models
+
+import "strings"
+
+// SourceUnknown is reported for candidates whose source was not recorded.
+const SourceUnknown = "unknown"
+
+// NormalizeSource lower-cases and trims the channel a candidate came
+// through, such as "linkedin" or "referral", so reports group spellings
+// together.
+func NormalizeSource(source string) string {
+    return strings.ToLower(strings.TrimSpace(source))
+}
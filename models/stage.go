@@ -0,0 +1,38 @@
+package models
+
+import (
+    "time"
+)
+
+// Pipeline stages a candidate moves through for a position.
+const (
+    StageApplied   = "applied"
+    StageScreening = "screening"
+    StageInterview = "interview"
+    StageOffer     = "offer"
+    StageHired     = "hired"
+    StageRejected  = "rejected"
+)
+
+var Stages = []string{StageApplied, StageScreening, StageInterview, StageOffer, StageHired, StageRejected}
+
+func IsValidStage(stage string) bool {
+    for _, s := range Stages {
+        if s == stage {
+            return true
+        }
+    }
+    return false
+}
+
+// CandidateStageHistory records every stage change of a candidate so time
+// spent in each stage can be reconstructed.
+type CandidateStageHistory struct {
+    ID          uint      `gorm:"primaryKey"`
+    CandidateID uint      `gorm:"not null;index"`
+    FromStage   string    `gorm:"size:32"`
+    ToStage     string    `gorm:"size:32;not null"`
+    UserID      *uint     `gorm:"index"`
+    User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL;"`
+    ChangedDate time.Time `gorm:"autoCreateTime"`
+}
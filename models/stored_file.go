@@ -0,0 +1,21 @@
+package models
+
+import (
+    "time"
+)
+
+// StoredFile is an object in storage, keyed by the SHA-256 hash of its
+// content so identical uploads share one object. RefCount is the number of
+// CV versions pointing at it; the object is deleted once it drops to zero.
+// Files uploaded before content addressing have no hash.
+type StoredFile struct {
+    ID          uint      `gorm:"primaryKey"`
+    Hash        *string   `gorm:"size:64;uniqueIndex"`
+    ObjectName  string    `gorm:"size:255;not null;uniqueIndex"`
+    URL         string    `gorm:"size:255;not null;uniqueIndex"`
+    ContentType string    `gorm:"size:128"`
+    Size        int64     `gorm:"not null;default:0"`
+    RefCount    int       `gorm:"not null;default:0;index"`
+    CreatedDate time.Time `gorm:"autoCreateTime"`
+    UpdatedDate time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+    "time"
+)
+
+type Tag struct {
+    ID          uint      `gorm:"primaryKey"`
+    Name        string    `gorm:"size:255;not null;uniqueIndex:idx_tags_company_name"`
+    Color       string    `gorm:"size:32"`
+    CompanyID   uint      `gorm:"not null;uniqueIndex:idx_tags_company_name"`
+    Company     Company   `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;"`
+    CreatedDate time.Time `gorm:"autoCreateTime"`
+}
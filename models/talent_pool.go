@@ -0,0 +1,15 @@
+package models
+
+import (
+    "time"
+)
+
+type TalentPool struct {
+    ID          uint        `gorm:"primaryKey"`
+    Name        string      `gorm:"size:255;not null;uniqueIndex:idx_talent_pools_company_name"`
+    Description string      `gorm:"type:text"`
+    CompanyID   uint        `gorm:"not null;uniqueIndex:idx_talent_pools_company_name"`
+    Company     Company     `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;"`
+    Candidates  []Candidate `gorm:"many2many:talent_pool_candidates;constraint:OnDelete:CASCADE;"`
+    CreatedDate time.Time   `gorm:"autoCreateTime"`
+}
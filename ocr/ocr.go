@@ -0,0 +1,57 @@
+package ocr
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "os"
+    "sync"
+)
+
+// Page is the recognized text of one page of a document. Confidence is the
+// engine's mean word confidence, from 0 to 1.
+type Page struct {
+    Number     int
+    Text       string
+    Confidence float64
+}
+
+// Engine recognizes the text of scanned documents. Implementations accept PDF
+// files as well as the image formats they support, and return one Page per
+// page in order.
+type Engine interface {
+    Name() string
+    Recognize(ctx context.Context, data []byte) ([]Page, error)
+}
+
+var (
+    defaultEngine     Engine
+    defaultEngineOnce sync.Once
+)
+
+// Default returns the engine selected by the OCR_ENGINE environment
+// variable, or nil when OCR is disabled with "none". The Tesseract engine is
+// used when the variable is unset.
+func Default() Engine {
+    defaultEngineOnce.Do(func() {
+        engine, err := New(os.Getenv("OCR_ENGINE"))
+        if err != nil {
+            log.Printf("Using Tesseract for OCR: %v", err)
+            engine = NewTesseractEngine()
+        }
+        defaultEngine = engine
+    })
+    return defaultEngine
+}
+
+// New returns the engine with the given name. The "none" engine is nil.
+func New(name string) (Engine, error) {
+    switch name {
+    case "", "tesseract":
+        return NewTesseractEngine(), nil
+    case "none":
+        return nil, nil
+    default:
+        return nil, fmt.Errorf("unknown OCR engine %q", name)
+    }
+}
@@ -0,0 +1,204 @@
+package ocr
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    defaultOCRMaxPages = 10
+    defaultOCRTimeout  = 2 * time.Minute
+)
+
+// TesseractEngine runs a locally installed Tesseract binary. PDF files are
+// first rasterized page by page with pdftoppm from Poppler. Only the first
+// MaxPages pages are read, and a document taking longer than Timeout is
+// abandoned.
+type TesseractEngine struct {
+    Binary     string
+    Languages  string
+    Rasterizer string
+    DPI        int
+    MaxPages   int
+    Timeout    time.Duration
+}
+
+// NewTesseractEngine configures the engine from the TESSERACT_PATH,
+// TESSERACT_LANGUAGES, PDFTOPPM_PATH, OCR_MAX_PAGES and OCR_TIMEOUT_SECONDS
+// environment variables. It reads English and Indonesian by default, up to
+// 10 pages within two minutes.
+func NewTesseractEngine() *TesseractEngine {
+    return &TesseractEngine{
+        Binary:     envOr("TESSERACT_PATH", "tesseract"),
+        Languages:  envOr("TESSERACT_LANGUAGES", "eng+ind"),
+        Rasterizer: envOr("PDFTOPPM_PATH", "pdftoppm"),
+        DPI:        300,
+        MaxPages:   positiveEnvOr("OCR_MAX_PAGES", defaultOCRMaxPages),
+        Timeout:    time.Duration(positiveEnvOr("OCR_TIMEOUT_SECONDS", int(defaultOCRTimeout/time.Second))) * time.Second,
+    }
+}
+
+func (e *TesseractEngine) Name() string {
+    return "tesseract"
+}
+
+func (e *TesseractEngine) Recognize(ctx context.Context, data []byte) ([]Page, error) {
+    ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+    defer cancel()
+
+    dir, err := os.MkdirTemp("", "ocr-")
+    if err != nil {
+        return nil, err
+    }
+    defer os.RemoveAll(dir)
+
+    images, err := e.pageImages(ctx, dir, data)
+    if err != nil {
+        return nil, err
+    }
+
+    pages := make([]Page, 0, len(images))
+    for i, image := range images {
+        text, confidence, err := e.recognizeImage(ctx, image)
+        if err != nil {
+            return nil, fmt.Errorf("page %d: %v", i+1, err)
+        }
+        pages = append(pages, Page{Number: i + 1, Text: text, Confidence: confidence})
+    }
+    return pages, nil
+}
+
+var rasterizedPagePattern = regexp.MustCompile(`-(\d+)\.png$`)
+
+// pageImages writes the document to dir and returns the paths of its page
+// images in page order.
+func (e *TesseractEngine) pageImages(ctx context.Context, dir string, data []byte) ([]string, error) {
+    if !bytes.HasPrefix(data, []byte("%PDF-")) {
+        path := filepath.Join(dir, "page")
+        if err := os.WriteFile(path, data, 0o600); err != nil {
+            return nil, err
+        }
+        return []string{path}, nil
+    }
+
+    path := filepath.Join(dir, "document.pdf")
+    if err := os.WriteFile(path, data, 0o600); err != nil {
+        return nil, err
+    }
+    if _, err := run(ctx, e.Rasterizer, "-r", strconv.Itoa(e.DPI), "-l", strconv.Itoa(e.MaxPages), "-png", path, filepath.Join(dir, "page")); err != nil {
+        return nil, err
+    }
+
+    images, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+    if err != nil {
+        return nil, err
+    }
+    // pdftoppm pads page numbers to the width of the page count, so sort
+    // numerically rather than by name.
+    sort.Slice(images, func(i, j int) bool {
+        return rasterizedPageNumber(images[i]) < rasterizedPageNumber(images[j])
+    })
+    return images, nil
+}
+
+func rasterizedPageNumber(path string) int {
+    m := rasterizedPagePattern.FindStringSubmatch(path)
+    if m == nil {
+        return 0
+    }
+    n, _ := strconv.Atoi(m[1])
+    return n
+}
+
+// recognizeImage runs Tesseract with TSV output, which carries a confidence
+// for every word, and rebuilds the text line by line from it.
+func (e *TesseractEngine) recognizeImage(ctx context.Context, image string) (string, float64, error) {
+    out, err := run(ctx, e.Binary, image, "stdout", "-l", e.Languages, "tsv")
+    if err != nil {
+        return "", 0, err
+    }
+    text, confidence := parseTSV(out)
+    return text, confidence, nil
+}
+
+// parseTSV reads Tesseract's TSV output: a header line, then one row per
+// layout element with the columns level, page_num, block_num, par_num,
+// line_num, word_num, left, top, width, height, conf and text. Words are
+// level 5 rows; other rows have a confidence of -1.
+func parseTSV(out []byte) (string, float64) {
+    var lines []string
+    var line []string
+    lineKey := ""
+    var confidenceSum float64
+    words := 0
+
+    scanner := bufio.NewScanner(bytes.NewReader(out))
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    for first := true; scanner.Scan(); first = false {
+        fields := strings.Split(scanner.Text(), "\t")
+        if first || len(fields) < 12 || fields[0] != "5" {
+            continue
+        }
+        word := strings.TrimSpace(fields[11])
+        confidence, err := strconv.ParseFloat(fields[10], 64)
+        if word == "" || err != nil || confidence < 0 {
+            continue
+        }
+
+        key := strings.Join(fields[1:5], "|")
+        if key != lineKey && len(line) > 0 {
+            lines = append(lines, strings.Join(line, " "))
+            line = nil
+        }
+        lineKey = key
+        line = append(line, word)
+        confidenceSum += confidence
+        words++
+    }
+    if len(line) > 0 {
+        lines = append(lines, strings.Join(line, " "))
+    }
+
+    if words == 0 {
+        return "", 0
+    }
+    return strings.Join(lines, "\n"), confidenceSum / float64(words) / 100
+}
+
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+    var stdout, stderr bytes.Buffer
+    cmd := exec.CommandContext(ctx, name, args...)
+    cmd.Stdout = &stdout
+    cmd.Stderr = &stderr
+    if err := cmd.Run(); err != nil {
+        if msg := strings.TrimSpace(stderr.String()); msg != "" {
+            return nil, fmt.Errorf("%s: %v: %s", filepath.Base(name), err, msg)
+        }
+        return nil, fmt.Errorf("%s: %v", filepath.Base(name), err)
+    }
+    return stdout.Bytes(), nil
+}
+
+func envOr(key, fallback string) string {
+    if value := os.Getenv(key); value != "" {
+        return value
+    }
+    return fallback
+}
+
+func positiveEnvOr(key string, fallback int) int {
+    if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+        return value
+    }
+    return fallback
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/filestore"
+    "cv-extractor/utils"
+    "flag"
+    "fmt"
+    "log"
+)
+
+// reconcileStorage queues orphaned and unreferenced objects for deletion,
+// and with -drain deletes the queued objects before exiting:
+//
+//	cv-extractor reconcile-storage [-dry-run] [-drain]
+func reconcileStorage(args []string) {
+    flags := flag.NewFlagSet("reconcile-storage", flag.ExitOnError)
+    dryRun := flags.Bool("dry-run", false, "only report what would be queued for deletion")
+    drain := flags.Bool("drain", false, "delete the queued objects that are due before exiting")
+    flags.Parse(args)
+
+    config.InitDB()
+    if err := utils.InitFirebase(); err != nil {
+        log.Fatalf("Failed to initialize Firebase: %v", err)
+    }
+
+    result, err := filestore.Reconcile(config.DB, *dryRun)
+    if err != nil {
+        log.Fatalf("Failed to reconcile storage: %v", err)
+    }
+    fmt.Printf("%d objects scanned, %d orphaned, %d unreferenced\n", result.Scanned, result.Orphaned, result.Unreferenced)
+
+    if !*drain || *dryRun {
+        return
+    }
+    for {
+        processed, err := filestore.ProcessDeletions(config.DB, 100)
+        if err != nil {
+            log.Fatalf("Failed to process file deletions: %v", err)
+        }
+        if processed < 100 {
+            return
+        }
+    }
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+    "cv-extractor/config"
+    "cv-extractor/controller"
+    "cv-extractor/models"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+)
+
+// reextract runs a re-extraction job in the foreground:
+//
+//	cv-extractor reextract -company 1 [-position 2] [-min-version 0] [-max-version 1]
+func reextract(args []string) {
+    flags := flag.NewFlagSet("reextract", flag.ExitOnError)
+    companyID := flags.Uint("company", 0, "company whose candidates are re-extracted (required)")
+    positionID := flags.Uint("position", 0, "only re-extract candidates of this position")
+    minVersion := flags.Int("min-version", -1, "only re-extract candidates processed by this extraction version or later")
+    maxVersion := flags.Int("max-version", -1, "only re-extract candidates processed by this extraction version or earlier")
+    flags.Parse(args)
+
+    if *companyID == 0 {
+        flags.Usage()
+        os.Exit(2)
+    }
+
+    config.InitDB()
+
+    job, err := controller.NewExtractionJob(*companyID, optionalUint(*positionID), optionalInt(*minVersion), optionalInt(*maxVersion))
+    if err != nil {
+        log.Fatalf("Failed to create extraction job: %v", err)
+    }
+
+    runErr := controller.RunExtractionJob(job.ID)
+    if err := config.DB.First(&job, job.ID).Error; err != nil {
+        log.Fatalf("Failed to load extraction job: %v", err)
+    }
+    fmt.Printf("Job %d %s: %d of %d candidates re-extracted, %d failed\n", job.ID, job.Status, job.Processed, job.Total, job.Failed)
+    if runErr != nil || job.Status != models.JobCompleted {
+        os.Exit(1)
+    }
+}
+
+func optionalUint(v uint) *uint {
+    if v == 0 {
+        return nil
+    }
+    return &v
+}
+
+func optionalInt(v int) *int {
+    if v < 0 {
+        return nil
+    }
+    return &v
+}
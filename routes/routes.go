@@ -3,6 +3,7 @@ package routes
 import (
     "cv-extractor/controller"
     "cv-extractor/middleware"
+    "cv-extractor/upload"
     "github.com/gin-gonic/gin"
 )
 
@@ -38,6 +39,12 @@ func authRoutes(r *gin.Engine) {
         userRoutes(auth)
         candidateRoutes(auth)
         departmentRoutes(auth)
+        tagRoutes(auth)
+        talentPoolRoutes(auth)
+        profileRoutes(auth)
+        skillRoutes(auth)
+        extractionRoutes(auth)
+        analyticsRoutes(auth)
     }
 }
 
@@ -58,6 +65,16 @@ func positionRoutes(r *gin.RouterGroup) {
     r.GET("/api/position/get-archived-positions", controller.GetArchivedPositions)
     r.PUT("/api/position/trash-position/:id", controller.TrashPosition)
     r.PUT("/api/position/resolve-position/:id", controller.ResolvePosition)
+    r.PUT("/api/position/change-position-status/:id", controller.ChangePositionStatus)
+    r.GET("/api/position/get-position-status-history/:id", controller.GetPositionStatusHistory)
+    r.PUT("/api/position/edit-position-opening/:id", controller.EditPositionOpening)
+    r.GET("/api/position/get-hiring-team/:id", controller.GetHiringTeam)
+    r.PUT("/api/position/edit-hiring-team/:id", controller.EditHiringTeam)
+    r.GET("/api/position/get-deleted-positions", controller.GetDeletedPositions)
+    r.PUT("/api/position/restore-position/:id", controller.RestorePosition)
+    r.POST("/api/position/bulk-archive-positions", controller.BulkArchivePositions)
+    r.GET("/api/position/get-matching-candidates/:id", controller.GetMatchingCandidates)
+    r.GET("/api/position/get-position-statistics/:id", controller.GetPositionStatistics)
 }
 
 func userRoutes(r *gin.RouterGroup) {
@@ -69,16 +86,35 @@ func userRoutes(r *gin.RouterGroup) {
 }
 
 func candidateRoutes(r *gin.RouterGroup) {
-    r.POST("/api/candidate/create-candidate", controller.CreateCandidate)
+    r.POST("/api/candidate/create-candidate", middleware.BodyLimit(upload.MaxRequestSize()), controller.CreateCandidate)
     r.GET("/api/candidate/get-all-candidates", controller.GetAllCandidates)
     r.GET("/api/candidate/get-candidates-by-position/:positionId", controller.GetCandidatesByPosition) // Add this line
     r.GET("/api/candidate/get-one-candidate/:id", controller.GetOneCandidate)
+    r.GET("/api/candidate/search-cvs", controller.SearchCVs)
+    r.GET("/api/candidate/get-review-queue", controller.GetReviewQueue)
+    r.GET("/api/candidate/get-candidate-cv-versions/:id", controller.GetCandidateCVVersions)
+    r.GET("/api/candidate/get-candidate-cv-diff/:id", controller.GetCandidateCVDiff)
+    r.GET("/api/candidate/get-extraction-accuracy", controller.GetExtractionAccuracy)
+    r.GET("/api/candidate/get-similar-candidates/:id", controller.GetSimilarCandidates)
     r.PUT("/api/candidate/edit-candidate/:id", controller.EditCandidate)
     r.PUT("/api/candidate/score-candidate/:id", controller.ScoreCandidate)
+    r.PUT("/api/candidate/rescore-candidates/:positionId", controller.RescoreCandidates)
     r.PUT("/api/candidate/qualify-candidate/:id", controller.QualifyCandidate)
+    r.PUT("/api/candidate/move-candidate-stage/:id", controller.MoveCandidateStage)
+    r.POST("/api/candidate/transfer-candidate/:id", controller.TransferCandidate)
+    r.PUT("/api/candidate/review-candidate/:id", controller.ReviewCandidate)
+    r.POST("/api/candidate/upload-candidate-cv/:id", middleware.BodyLimit(upload.MaxRequestSize()), controller.UploadCandidateCV)
     r.DELETE("/api/candidate/delete-candidate/:id", controller.DeleteCandidate)
+    r.GET("/api/candidate/get-deleted-candidates", controller.GetDeletedCandidates)
+    r.PUT("/api/candidate/restore-candidate/:id", controller.RestoreCandidate)
+    r.POST("/api/candidate/bulk-delete-candidates", controller.BulkDeleteCandidates)
+    r.POST("/api/candidate/bulk-qualify-candidates", controller.BulkQualifyCandidates)
+    r.POST("/api/candidate/bulk-move-candidate-stage", controller.BulkMoveCandidateStage)
+    r.POST("/api/candidate/bulk-rescore-candidates", controller.BulkRescoreCandidates)
     r.POST("/api/candidate/get-candidates-by-filters", controller.GetCandidatesByFilters)
     r.POST("/api/candidate/get-archived-candidates-by-filters", controller.GetArchivedCandidatesByFilters)
+    r.POST("/api/candidate/create-candidate-note/:id", controller.CreateCandidateNote)
+    r.GET("/api/candidate/get-candidate-notes/:id", controller.GetCandidateNotes)
 
 }
 
@@ -88,5 +124,58 @@ func departmentRoutes(r *gin.RouterGroup) {
     r.GET("/api/department/get-one-department/:id", controller.GetOneDepartment)
     r.PUT("/api/department/edit-department/:id", controller.EditDepartment)
     r.DELETE("/api/department/delete-department/:id", controller.DeleteDepartment)
+    r.GET("/api/department/get-deleted-departments", controller.GetDeletedDepartments)
+    r.PUT("/api/department/restore-department/:id", controller.RestoreDepartment)
+    r.GET("/api/department/get-department-statistics/:id", controller.GetDepartmentStatistics)
 }
 
+func tagRoutes(r *gin.RouterGroup) {
+    r.POST("/api/tag/create-tag", controller.CreateTag)
+    r.GET("/api/tag/get-all-tags", controller.GetAllTags)
+    r.PUT("/api/tag/edit-tag/:id", controller.EditTag)
+    r.DELETE("/api/tag/delete-tag/:id", controller.DeleteTag)
+    r.POST("/api/tag/add-tags-to-candidates", controller.AddTagsToCandidates)
+    r.POST("/api/tag/remove-tags-from-candidates", controller.RemoveTagsFromCandidates)
+}
+
+func talentPoolRoutes(r *gin.RouterGroup) {
+    r.POST("/api/talent-pool/create-talent-pool", controller.CreateTalentPool)
+    r.GET("/api/talent-pool/get-all-talent-pools", controller.GetAllTalentPools)
+    r.GET("/api/talent-pool/get-one-talent-pool/:id", controller.GetOneTalentPool)
+    r.PUT("/api/talent-pool/edit-talent-pool/:id", controller.EditTalentPool)
+    r.DELETE("/api/talent-pool/delete-talent-pool/:id", controller.DeleteTalentPool)
+    r.POST("/api/talent-pool/add-candidates/:id", controller.AddCandidatesToTalentPool)
+    r.POST("/api/talent-pool/remove-candidates/:id", controller.RemoveCandidatesFromTalentPool)
+}
+
+func profileRoutes(r *gin.RouterGroup) {
+    r.GET("/api/profile/get-all-profiles", controller.GetAllProfiles)
+    r.GET("/api/profile/get-one-profile/:id", controller.GetOneProfile)
+    r.GET("/api/profile/get-profile-duplicates/:id", controller.GetProfileDuplicates)
+    r.GET("/api/profile/get-duplicate-profiles", controller.GetDuplicateProfiles)
+    r.POST("/api/profile/merge-profiles", controller.MergeProfiles)
+}
+
+func skillRoutes(r *gin.RouterGroup) {
+    r.POST("/api/skill/create-skill", controller.CreateSkill)
+    r.GET("/api/skill/get-all-skills", controller.GetAllSkills)
+    r.GET("/api/skill/get-one-skill/:id", controller.GetOneSkill)
+    r.PUT("/api/skill/edit-skill/:id", controller.EditSkill)
+    r.DELETE("/api/skill/delete-skill/:id", controller.DeleteSkill)
+    r.POST("/api/skill/normalize-skills", controller.NormalizeSkills)
+    r.GET("/api/skill/get-skill-frequency/:positionId", controller.GetSkillFrequency)
+}
+
+func extractionRoutes(r *gin.RouterGroup) {
+    r.POST("/api/extraction/create-extraction-job", controller.CreateExtractionJob)
+    r.GET("/api/extraction/get-all-extraction-jobs", controller.GetAllExtractionJobs)
+    r.GET("/api/extraction/get-one-extraction-job/:id", controller.GetOneExtractionJob)
+}
+
+func analyticsRoutes(r *gin.RouterGroup) {
+    r.GET("/api/analytics/get-time-series", controller.GetAnalyticsTimeSeries)
+    r.GET("/api/analytics/get-time-in-stage", controller.GetAnalyticsTimeInStage)
+    r.GET("/api/analytics/get-source-effectiveness", controller.GetAnalyticsSourceEffectiveness)
+    r.GET("/api/analytics/get-funnel", controller.GetAnalyticsFunnel)
+    r.GET("/api/analytics/get-breakdown", controller.GetAnalyticsBreakdown)
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+    "bytes"
+    "context"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "net"
+    "strings"
+    "testing"
+)
+
+// clamdStub accepts a single INSTREAM session, checks its framing and
+// answers with reply. The streamed file is sent on the returned channel, or
+// nil when the framing was invalid.
+func clamdStub(t *testing.T, reply string) (*ClamdScanner, <-chan []byte) {
+    t.Helper()
+
+    listener, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("listening: %v", err)
+    }
+    t.Cleanup(func() { listener.Close() })
+
+    received := make(chan []byte, 1)
+    go func() {
+        conn, err := listener.Accept()
+        if err != nil {
+            received <- nil
+            return
+        }
+        defer conn.Close()
+
+        data, err := readInstream(conn)
+        if err != nil {
+            t.Errorf("reading INSTREAM session: %v", err)
+            received <- nil
+            return
+        }
+        received <- data
+        conn.Write([]byte(reply + "\x00"))
+    }()
+
+    s, err := NewClamdScanner("tcp://" + listener.Addr().String())
+    if err != nil {
+        t.Fatalf("creating scanner: %v", err)
+    }
+    return s, received
+}
+
+// readInstream reads the INSTREAM command and its chunks up to the
+// zero-length chunk, checking that no chunk exceeds clamdChunkSize.
+func readInstream(r io.Reader) ([]byte, error) {
+    command := make([]byte, len("zINSTREAM\x00"))
+    if _, err := io.ReadFull(r, command); err != nil {
+        return nil, err
+    }
+    if string(command) != "zINSTREAM\x00" {
+        return nil, fmt.Errorf("unexpected command %q", command)
+    }
+
+    var data bytes.Buffer
+    for {
+        var size [4]byte
+        if _, err := io.ReadFull(r, size[:]); err != nil {
+            return nil, err
+        }
+        n := binary.BigEndian.Uint32(size[:])
+        if n == 0 {
+            return data.Bytes(), nil
+        }
+        if n > clamdChunkSize {
+            return nil, fmt.Errorf("chunk of %d bytes exceeds %d", n, clamdChunkSize)
+        }
+        if _, err := io.CopyN(&data, r, int64(n)); err != nil {
+            return nil, err
+        }
+    }
+}
+
+func TestClamdScannerClean(t *testing.T) {
+    s, received := clamdStub(t, "stream: OK")
+
+    // Larger than one chunk, so the file is split.
+    file := bytes.Repeat([]byte("curriculum vitae "), clamdChunkSize/8)
+    result, err := s.Scan(context.Background(), bytes.NewReader(file))
+    if err != nil {
+        t.Fatalf("Scan: %v", err)
+    }
+    if !result.Clean || result.Signature != "" {
+        t.Errorf("Scan = %+v, want clean", result)
+    }
+    if data := <-received; !bytes.Equal(data, file) {
+        t.Errorf("stub received %d bytes, want %d", len(data), len(file))
+    }
+}
+
+func TestClamdScannerFound(t *testing.T) {
+    s, received := clamdStub(t, "stream: Eicar-Signature FOUND")
+
+    result, err := s.Scan(context.Background(), strings.NewReader("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR"))
+    if err != nil {
+        t.Fatalf("Scan: %v", err)
+    }
+    if result.Clean || result.Signature != "Eicar-Signature" {
+        t.Errorf("Scan = %+v, want Eicar-Signature found", result)
+    }
+    <-received
+}
+
+func TestClamdScannerError(t *testing.T) {
+    s, received := clamdStub(t, "INSTREAM size limit exceeded. ERROR")
+
+    if _, err := s.Scan(context.Background(), strings.NewReader("cv")); err == nil {
+        t.Error("Scan succeeded, want the clamd error")
+    }
+    <-received
+}
+
+func TestParseClamdReply(t *testing.T) {
+    tests := []struct {
+        reply string
+        want  Result
+        err   bool
+    }{
+        {reply: "stream: OK", want: Result{Clean: true}},
+        {reply: "stream: Win.Test.EICAR_HDB-1 FOUND", want: Result{Signature: "Win.Test.EICAR_HDB-1"}},
+        {reply: "INSTREAM size limit exceeded. ERROR", err: true},
+    }
+    for _, test := range tests {
+        got, err := parseClamdReply(test.reply)
+        if (err != nil) != test.err || got != test.want {
+            t.Errorf("parseClamdReply(%q) = %+v, %v", test.reply, got, err)
+        }
+    }
+}
+
+func TestNewClamdScannerAddress(t *testing.T) {
+    if _, err := NewClamdScanner("127.0.0.1:3310"); err == nil {
+        t.Error("NewClamdScanner accepted an address without a network")
+    }
+
+    s, err := NewClamdScanner("unix:///run/clamav/clamd.ctl")
+    if err != nil {
+        t.Fatalf("NewClamdScanner: %v", err)
+    }
+    if s.Network != "unix" || s.Address != "/run/clamav/clamd.ctl" {
+        t.Errorf("NewClamdScanner = %s %s", s.Network, s.Address)
+    }
+}
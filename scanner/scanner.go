@@ -0,0 +1,68 @@
+package scanner
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "sync"
+)
+
+// Result is the verdict of a malware scan. Signature names the threat found
+// in an infected file.
+type Result struct {
+    Clean     bool
+    Signature string
+}
+
+// Scanner checks uploaded files for malware before they are stored.
+type Scanner interface {
+    Name() string
+    Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+var (
+    defaultScanner     Scanner
+    defaultScannerOnce sync.Once
+)
+
+// Default returns the scanner selected by the CV_SCANNER environment
+// variable, or nil when scanning is disabled, which is the default. The
+// "clamd" scanner connects to the address in CLAMD_ADDRESS.
+func Default() Scanner {
+    defaultScannerOnce.Do(func() {
+        s, err := New(os.Getenv("CV_SCANNER"))
+        if err != nil {
+            // Fail closed: a misconfigured scanner must not silently let
+            // files through.
+            s = unavailableScanner{err: err}
+        }
+        defaultScanner = s
+    })
+    return defaultScanner
+}
+
+// New returns the scanner with the given name. The "none" scanner is nil.
+func New(name string) (Scanner, error) {
+    switch name {
+    case "", "none":
+        return nil, nil
+    case "clamd":
+        return NewClamdScanner(os.Getenv("CLAMD_ADDRESS"))
+    default:
+        return nil, fmt.Errorf("unknown scanner %q", name)
+    }
+}
+
+// unavailableScanner fails every scan with the configuration error.
+type unavailableScanner struct {
+    err error
+}
+
+func (s unavailableScanner) Name() string {
+    return "unavailable"
+}
+
+func (s unavailableScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+    return Result{}, s.err
+}
@@ -0,0 +1,94 @@
+package scoring
+
+import (
+    "math"
+    "strings"
+
+    "cv-extractor/models"
+)
+
+// Weights of each component in the final score, which ranges from 0 to 100.
+// Components without a requirement count as fully met.
+var weights = map[string]float64{
+    ComponentExperience: 30,
+    ComponentEducation:  20,
+    ComponentSkills:     50,
+}
+
+const (
+    ComponentExperience = "experience"
+    ComponentEducation  = "education"
+    ComponentSkills     = "skills"
+)
+
+// Requirements are what a position asks of its candidates.
+type Requirements struct {
+    MinExperienceMonths int
+    MinEducation        models.EducationLevel
+    Skills              []string
+}
+
+// Facts are what extraction and recruiters know about a candidate.
+type Facts struct {
+    ExperienceMonths int
+    Education        models.EducationLevel
+    Skills           []string
+}
+
+// Result is a candidate's score with the fraction of each component met.
+type Result struct {
+    Score      float64            `json:"score"`
+    Components map[string]float64 `json:"components"`
+}
+
+// Score rates how well the facts meet the requirements.
+func Score(requirements Requirements, facts Facts) Result {
+    components := map[string]float64{
+        ComponentExperience: experienceMatch(requirements.MinExperienceMonths, facts.ExperienceMonths),
+        ComponentEducation:  educationMatch(requirements.MinEducation, facts.Education),
+        ComponentSkills:     skillsMatch(requirements.Skills, facts.Skills),
+    }
+
+    var score, total float64
+    for name, fraction := range components {
+        score += weights[name] * fraction
+        total += weights[name]
+    }
+
+    return Result{Score: math.Round(score/total*1000) / 10, Components: components}
+}
+
+func experienceMatch(requiredMonths, months int) float64 {
+    if requiredMonths <= 0 {
+        return 1
+    }
+    return math.Min(1, float64(months)/float64(requiredMonths))
+}
+
+// educationMatch is all or nothing: a degree either meets the minimum level
+// or it does not.
+func educationMatch(required, level models.EducationLevel) float64 {
+    if level.AtLeast(required) {
+        return 1
+    }
+    return 0
+}
+
+func skillsMatch(required, skills []string) float64 {
+    if len(required) == 0 {
+        return 1
+    }
+
+    have := make(map[string]bool, len(skills))
+    for _, skill := range skills {
+        have[strings.ToLower(strings.TrimSpace(skill))] = true
+    }
+
+    matched := 0
+    for _, skill := range required {
+        if have[strings.ToLower(strings.TrimSpace(skill))] {
+            matched++
+        }
+    }
+    return float64(matched) / float64(len(required))
+}
@@ -0,0 +1,193 @@
+package thumbnail
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    _ "image/jpeg"
+    "image/png"
+    "io"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "cv-extractor/extractor"
+)
+
+// Width is the width in pixels of generated thumbnails. Their height follows
+// the page's aspect ratio.
+const Width = 240
+
+// maxPixels bounds the size of images decoded in-process, so an image
+// declaring huge dimensions cannot exhaust memory.
+const maxPixels = 50 << 20
+
+// timeout bounds how long rendering one thumbnail may take, including the
+// LibreOffice and pdftoppm runs.
+const timeout = time.Minute
+
+var (
+    ErrUnsupportedFormat = errors.New("no thumbnail renderer for this CV format")
+    ErrImageTooLarge     = errors.New("image dimensions are too large for a thumbnail")
+)
+
+// Generate renders the first page of a CV as a PNG thumbnail. Images are
+// scaled in-process; PDFs are rasterized with pdftoppm and DOCX files are
+// converted to PDF with LibreOffice first, both located through the
+// PDFTOPPM_PATH and LIBREOFFICE_PATH environment variables. Rendering gives
+// up after timeout, and images larger than maxPixels are rejected.
+func Generate(ctx context.Context, data []byte) ([]byte, error) {
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    var img image.Image
+    var err error
+
+    switch extractor.DetectFormat(data) {
+    case extractor.FormatJPEG, extractor.FormatPNG:
+        img, err = decodeImage(bytes.NewReader(data))
+    case extractor.FormatPDF:
+        img, err = renderPDF(ctx, data)
+    case extractor.FormatDOCX:
+        img, err = renderDOCX(ctx, data)
+    default:
+        return nil, ErrUnsupportedFormat
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var out bytes.Buffer
+    if err := png.Encode(&out, scale(img, Width)); err != nil {
+        return nil, err
+    }
+    return out.Bytes(), nil
+}
+
+// decodeImage decodes a JPEG or PNG image after checking from its header
+// that its dimensions stay within maxPixels.
+func decodeImage(r io.ReadSeeker) (image.Image, error) {
+    config, _, err := image.DecodeConfig(r)
+    if err != nil {
+        return nil, err
+    }
+    if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > maxPixels {
+        return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, config.Width, config.Height)
+    }
+    if _, err := r.Seek(0, io.SeekStart); err != nil {
+        return nil, err
+    }
+    img, _, err := image.Decode(r)
+    return img, err
+}
+
+// renderPDF rasterizes the first page of a PDF at twice the thumbnail width,
+// leaving the final downscale to scale for smoother results.
+func renderPDF(ctx context.Context, data []byte) (image.Image, error) {
+    dir, err := os.MkdirTemp("", "thumbnail-")
+    if err != nil {
+        return nil, err
+    }
+    defer os.RemoveAll(dir)
+
+    path := filepath.Join(dir, "document.pdf")
+    if err := os.WriteFile(path, data, 0o600); err != nil {
+        return nil, err
+    }
+    return rasterizeFirstPage(ctx, dir, path)
+}
+
+func renderDOCX(ctx context.Context, data []byte) (image.Image, error) {
+    dir, err := os.MkdirTemp("", "thumbnail-")
+    if err != nil {
+        return nil, err
+    }
+    defer os.RemoveAll(dir)
+
+    path := filepath.Join(dir, "document.docx")
+    if err := os.WriteFile(path, data, 0o600); err != nil {
+        return nil, err
+    }
+    // A private profile directory lets conversions run concurrently with
+    // each other and with a desktop LibreOffice session.
+    profile := "-env:UserInstallation=file://" + filepath.ToSlash(filepath.Join(dir, "profile"))
+    if err := run(ctx, envOr("LIBREOFFICE_PATH", "soffice"), profile, "--headless", "--convert-to", "pdf", "--outdir", dir, path); err != nil {
+        return nil, err
+    }
+    return rasterizeFirstPage(ctx, dir, filepath.Join(dir, "document.pdf"))
+}
+
+func rasterizeFirstPage(ctx context.Context, dir, pdfPath string) (image.Image, error) {
+    prefix := filepath.Join(dir, "page")
+    if err := run(ctx, envOr("PDFTOPPM_PATH", "pdftoppm"), "-f", "1", "-l", "1", "-singlefile", "-png",
+        "-scale-to-x", fmt.Sprint(2*Width), "-scale-to-y", "-1", pdfPath, prefix); err != nil {
+        return nil, err
+    }
+
+    file, err := os.Open(prefix + ".png")
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+    return decodeImage(file)
+}
+
+// scale shrinks an image to the given width by averaging the source pixels
+// that fall into each destination pixel. Images already narrower are only
+// converted.
+func scale(src image.Image, width int) image.Image {
+    bounds := src.Bounds()
+    if bounds.Dx() <= width {
+        width = bounds.Dx()
+    }
+    height := bounds.Dy() * width / bounds.Dx()
+    if height < 1 {
+        height = 1
+    }
+
+    dst := image.NewRGBA(image.Rect(0, 0, width, height))
+    for y := 0; y < height; y++ {
+        y0 := bounds.Min.Y + y*bounds.Dy()/height
+        y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/height, y0+1)
+        for x := 0; x < width; x++ {
+            x0 := bounds.Min.X + x*bounds.Dx()/width
+            x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/width, x0+1)
+
+            var r, g, b, a, n uint64
+            for sy := y0; sy < y1; sy++ {
+                for sx := x0; sx < x1; sx++ {
+                    pr, pg, pb, pa := src.At(sx, sy).RGBA()
+                    r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+                    n++
+                }
+            }
+            dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+        }
+    }
+    return dst
+}
+
+func run(ctx context.Context, name string, args ...string) error {
+    var stderr bytes.Buffer
+    cmd := exec.CommandContext(ctx, name, args...)
+    cmd.Stderr = &stderr
+    if err := cmd.Run(); err != nil {
+        if msg := strings.TrimSpace(stderr.String()); msg != "" {
+            return fmt.Errorf("%s: %v: %s", filepath.Base(name), err, msg)
+        }
+        return fmt.Errorf("%s: %v", filepath.Base(name), err)
+    }
+    return nil
+}
+
+func envOr(key, fallback string) string {
+    if value := os.Getenv(key); value != "" {
+        return value
+    }
+    return fallback
+}
@@ -0,0 +1,190 @@
+package upload
+
+import (
+    "archive/zip"
+    "bufio"
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "cv-extractor/extractor"
+)
+
+const defaultMaxCVSizeMB = 10
+
+// multipartOverhead is the room left in request bodies for the multipart
+// framing and the other form fields sent with a CV.
+const multipartOverhead = 1 << 20
+
+var (
+    ErrTooLarge        = errors.New("CV file is too large")
+    ErrUnsupportedType = errors.New("CV files must be PDF, DOCX, JPEG, PNG or plain text")
+    ErrTypeMismatch    = errors.New("CV file content does not match its extension")
+    ErrEncryptedPDF    = errors.New("CV file is a password-protected PDF; please upload an unprotected copy")
+    ErrProtectedOffice = errors.New("CV file is a password-protected or legacy Word document; please upload it as DOCX or PDF")
+)
+
+// extensionFormats maps the file extensions accepted for CVs to the format
+// their content must have.
+var extensionFormats = map[string]string{
+    ".pdf":  extractor.FormatPDF,
+    ".docx": extractor.FormatDOCX,
+    ".jpg":  extractor.FormatJPEG,
+    ".jpeg": extractor.FormatJPEG,
+    ".png":  extractor.FormatPNG,
+    ".txt":  extractor.FormatText,
+}
+
+// contentTypes are the MIME types CV files are stored with, derived from
+// their content rather than from what the client claimed.
+var contentTypes = map[string]string{
+    extractor.FormatPDF:  "application/pdf",
+    extractor.FormatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+    extractor.FormatJPEG: "image/jpeg",
+    extractor.FormatPNG:  "image/png",
+    extractor.FormatText: "text/plain; charset=utf-8",
+}
+
+// formatExtensions are the extensions CV files are stored with, so a stored
+// object's name reflects its content whatever the client called it.
+var formatExtensions = map[string]string{
+    extractor.FormatPDF:  ".pdf",
+    extractor.FormatDOCX: ".docx",
+    extractor.FormatJPEG: ".jpg",
+    extractor.FormatPNG:  ".png",
+    extractor.FormatText: ".txt",
+}
+
+// MaxCVSize returns the largest CV file accepted, in bytes, configured in
+// megabytes through CV_MAX_SIZE_MB.
+func MaxCVSize() int64 {
+    mb, err := strconv.ParseInt(os.Getenv("CV_MAX_SIZE_MB"), 10, 64)
+    if err != nil || mb <= 0 {
+        mb = defaultMaxCVSizeMB
+    }
+    return mb << 20
+}
+
+// MaxRequestSize returns the request body limit for endpoints receiving a CV.
+func MaxRequestSize() int64 {
+    return MaxCVSize() + multipartOverhead
+}
+
+// Validate checks a CV file's content, reading the size bytes of r as a
+// stream so the file is never held in memory: its extension must be one
+// accepted for CVs, its format is sniffed from its magic bytes and must agree
+// with that extension, and protected documents, which cannot be parsed, are
+// rejected. It returns the detected format.
+func Validate(filename string, r io.ReaderAt, size int64) (string, error) {
+    ext := strings.ToLower(filepath.Ext(filename))
+    expected, known := extensionFormats[ext]
+    if !known {
+        return extractor.FormatUnknown, ErrUnsupportedType
+    }
+
+    head := make([]byte, sniffSize)
+    n, err := r.ReadAt(head, 0)
+    if err != nil && err != io.EOF {
+        return extractor.FormatUnknown, err
+    }
+    head = head[:n]
+
+    if bytes.HasPrefix(head, []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")) {
+        // Compound File Binary: a legacy .doc, or a DOCX that Word
+        // encrypted with a password.
+        return extractor.FormatUnknown, ErrProtectedOffice
+    }
+
+    format, err := detectFormat(head, io.NewSectionReader(r, 0, size))
+    if err != nil {
+        return format, err
+    }
+    if format == extractor.FormatUnknown {
+        return format, ErrUnsupportedType
+    }
+    if expected != format {
+        return format, fmt.Errorf("%w: %s file contains %s", ErrTypeMismatch, ext, format)
+    }
+
+    if format == extractor.FormatPDF && encryptPattern.MatchReader(bufio.NewReader(io.NewSectionReader(r, 0, size))) {
+        return format, ErrEncryptedPDF
+    }
+    return format, nil
+}
+
+// sniffSize is how much of a file's start Validate reads to recognize its
+// format.
+const sniffSize = 512
+
+// detectFormat is extractor.DetectFormat for a file too large to hold in
+// memory, of which head is the start: DOCX files are recognized from their
+// ZIP directory and text by decoding the whole file as it streams.
+func detectFormat(head []byte, file *io.SectionReader) (string, error) {
+    switch format := extractor.DetectFormat(head); format {
+    case extractor.FormatPDF, extractor.FormatJPEG, extractor.FormatPNG:
+        return format, nil
+    }
+
+    if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+        archive, err := zip.NewReader(file, file.Size())
+        if err != nil {
+            return extractor.FormatUnknown, nil
+        }
+        for _, f := range archive.File {
+            if f.Name == "word/document.xml" {
+                return extractor.FormatDOCX, nil
+            }
+        }
+        // Other ZIP based files, such as spreadsheets, lack the main
+        // document part.
+        return extractor.FormatUnknown, nil
+    }
+
+    text, err := isUTF8(file)
+    if err != nil || !text {
+        return extractor.FormatUnknown, err
+    }
+    return extractor.FormatText, nil
+}
+
+// isUTF8 reports whether r reads as valid UTF-8 to its end.
+func isUTF8(r io.Reader) (bool, error) {
+    br := bufio.NewReader(r)
+    for {
+        c, size, err := br.ReadRune()
+        if err == io.EOF {
+            return true, nil
+        }
+        if err != nil {
+            return false, err
+        }
+        if c == utf8.RuneError && size == 1 {
+            return false, nil
+        }
+    }
+}
+
+// ContentType returns the MIME type to store a file of the given format with.
+func ContentType(format string) string {
+    if contentType, ok := contentTypes[format]; ok {
+        return contentType
+    }
+    return "application/octet-stream"
+}
+
+// Extension returns the file extension to store a file of the given format
+// with.
+func Extension(format string) string {
+    return formatExtensions[format]
+}
+
+// encryptPattern matches the /Encrypt entry a trailer or cross-reference
+// stream dictionary has when a PDF is encrypted.
+var encryptPattern = regexp.MustCompile(`/Encrypt\s*(?:\d+\s+\d+\s+R|<<)`)
@@ -1,15 +1,18 @@
 package utils
 
 import (
+    "bytes"
     "context"
+    "errors"
     "fmt"
     "io"
-    "mime/multipart"
     "os"
     "time"
 
+    gcs "cloud.google.com/go/storage"
     firebase "firebase.google.com/go"
     "firebase.google.com/go/storage"
+    "google.golang.org/api/iterator"
     "google.golang.org/api/option"
 )
 
@@ -36,37 +39,37 @@ func InitFirebase() error {
     return nil
 }
 
-func UploadFileToFirebase(fileHeader *multipart.FileHeader) (string, error) {
-    file, err := fileHeader.Open()
-    if err != nil {
-        return "", err
-    }
-    defer file.Close()
+// UploadBytesToFirebase stores data under the given object name and returns
+// its URL.
+func UploadBytesToFirebase(objectName, contentType string, data []byte) (string, error) {
+    return UploadToFirebase(objectName, contentType, bytes.NewReader(data))
+}
 
+// UploadToFirebase stores what r reads under the given object name and
+// returns its URL, streaming it without holding it in memory.
+func UploadToFirebase(objectName, contentType string, r io.Reader) (string, error) {
     ctx := context.Background()
     bucket, err := storageClient.DefaultBucket()
     if err != nil {
         return "", err
     }
 
-    objectName := fmt.Sprintf("cv_files/%d-%s", time.Now().Unix(), fileHeader.Filename)
     wc := bucket.Object(objectName).NewWriter(ctx)
-    wc.ContentType = fileHeader.Header.Get("Content-Type")
-    if _, err = io.Copy(wc, file); err != nil {
+    wc.ContentType = contentType
+    if _, err := io.Copy(wc, r); err != nil {
+        wc.Close()
         return "", err
     }
     if err := wc.Close(); err != nil {
         return "", err
     }
 
-    // Generate a signed URL
-    attrs, err := bucket.Object(objectName).Attrs(ctx)
-    if err != nil {
-        return "", err
-    }
+    return FileURL(objectName), nil
+}
 
-    url := fmt.Sprintf("https://storage.googleapis.com/%s/%s", attrs.Bucket, attrs.Name)
-    return url, nil
+// FileURL returns the URL of the object with the given name.
+func FileURL(objectName string) string {
+    return fmt.Sprintf("https://storage.googleapis.com/%s/%s", bucketName, objectName)
 }
 
 func DeleteFileFromFirebase(fileKey string) error {
@@ -77,9 +80,38 @@ func DeleteFileFromFirebase(fileKey string) error {
     }
 
     o := bucket.Object(fileKey)
-    if err := o.Delete(ctx); err != nil {
+    if err := o.Delete(ctx); err != nil && !errors.Is(err, gcs.ErrObjectNotExist) {
         return err
     }
 
     return nil
 }
+
+// StorageObject is an object listed from the storage bucket.
+type StorageObject struct {
+    Name    string
+    Updated time.Time
+}
+
+// ListFirebaseObjects returns the objects whose names start with prefix.
+func ListFirebaseObjects(prefix string) ([]StorageObject, error) {
+    ctx := context.Background()
+    bucket, err := storageClient.DefaultBucket()
+    if err != nil {
+        return nil, err
+    }
+
+    var objects []StorageObject
+    it := bucket.Objects(ctx, &gcs.Query{Prefix: prefix})
+    for {
+        attrs, err := it.Next()
+        if err == iterator.Done {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+        objects = append(objects, StorageObject{Name: attrs.Name, Updated: attrs.Updated})
+    }
+    return objects, nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+    "sort"
+    "strings"
+    "unicode"
+)
+
+// NormalizeEmail lowercases and trims an email address for comparison.
+func NormalizeEmail(email string) string {
+    return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone keeps only the digits of a phone number and rewrites the
+// Indonesian country code to its local trunk prefix, so "+62 812-3456" and
+// "08123456" compare equal.
+func NormalizePhone(phone string) string {
+    var b strings.Builder
+    for _, r := range phone {
+        if r >= '0' && r <= '9' {
+            b.WriteRune(r)
+        }
+    }
+    digits := b.String()
+    if strings.HasPrefix(digits, "62") && len(digits) > 9 {
+        digits = "0" + digits[2:]
+    }
+    return digits
+}
+
+// NormalizeName lowercases a person's name, strips punctuation and sorts the
+// remaining words so that "Doe, John" and "john doe" normalize identically.
+func NormalizeName(name string) string {
+    words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+    sort.Strings(words)
+    return strings.Join(words, " ")
+}
+
+// NameSimilarity returns a value between 0 and 1 describing how close two
+// normalized names are, based on their Levenshtein distance.
+func NameSimilarity(a, b string) float64 {
+    if a == "" || b == "" {
+        return 0
+    }
+    if a == b {
+        return 1
+    }
+
+    ra, rb := []rune(a), []rune(b)
+    longest := len(ra)
+    if len(rb) > longest {
+        longest = len(rb)
+    }
+    return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+    prev := make([]int, len(b)+1)
+    curr := make([]int, len(b)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+
+    for i := 1; i <= len(a); i++ {
+        curr[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+        }
+        prev, curr = curr, prev
+    }
+
+    return prev[len(b)]
+}
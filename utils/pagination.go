@@ -0,0 +1,365 @@
+package utils
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/schema"
+)
+
+const (
+    defaultPageLimit = 50
+    maxPageLimit     = 200
+)
+
+// SortField describes a column a list endpoint may be sorted by. Field is the
+// struct field holding the column's value, used to build the next cursor.
+type SortField struct {
+    Column string
+    Field  string
+    Kind   string
+}
+
+const (
+    SortNumber = "number"
+    SortTime   = "time"
+    SortString = "string"
+)
+
+// NameAndCreatedSort returns the sort fields shared by every entity that has
+// Name and CreatedDate columns.
+func NameAndCreatedSort(table string) map[string]SortField {
+    return map[string]SortField{
+        "created": {Column: table + ".created_date", Field: "CreatedDate", Kind: SortTime},
+        "name":    {Column: table + ".name", Field: "Name", Kind: SortString},
+    }
+}
+
+// ListParams holds the pagination, sorting and field selection requested
+// through the limit, cursor, sort and fields query parameters.
+type ListParams struct {
+    Limit   int
+    SortKey string
+    Sort    SortField
+    Desc    bool
+    Fields  []string
+    cursor  *pageCursor
+    idField string
+}
+
+// Page is the envelope returned by every list endpoint.
+type Page struct {
+    Items      interface{} `json:"items"`
+    NextCursor *string     `json:"next_cursor"`
+    Total      int64       `json:"total"`
+}
+
+type pageCursor struct {
+    Sort  string      `json:"s"`
+    Value interface{} `json:"v"`
+    ID    uint        `json:"id"`
+}
+
+// ParseListParams reads the list query parameters. sort accepts one of the
+// keys of sortFields, prefixed with "-" for descending order; it defaults to
+// ascending creation date.
+func ParseListParams(c *gin.Context, table string, sortFields map[string]SortField) (ListParams, error) {
+    params := ListParams{Limit: defaultPageLimit, SortKey: "created", idField: table + ".id"}
+
+    if limit := c.Query("limit"); limit != "" {
+        n, err := strconv.Atoi(limit)
+        if err != nil || n < 1 {
+            return params, fmt.Errorf("limit must be a positive integer")
+        }
+        params.Limit = min(n, maxPageLimit)
+    }
+
+    if sort := c.Query("sort"); sort != "" {
+        params.Desc = strings.HasPrefix(sort, "-")
+        params.SortKey = strings.TrimPrefix(sort, "-")
+    }
+    field, ok := sortFields[params.SortKey]
+    if !ok {
+        keys := make([]string, 0, len(sortFields))
+        for key := range sortFields {
+            keys = append(keys, key)
+        }
+        return params, fmt.Errorf("sort must be one of %s", strings.Join(keys, ", "))
+    }
+    params.Sort = field
+
+    if fields := c.Query("fields"); fields != "" {
+        for _, f := range strings.Split(fields, ",") {
+            if f = strings.TrimSpace(f); f != "" {
+                params.Fields = append(params.Fields, f)
+            }
+        }
+    }
+
+    if cursor := c.Query("cursor"); cursor != "" {
+        decoded, err := decodeCursor(cursor)
+        if err != nil || decoded.Sort != params.sortName() {
+            return params, fmt.Errorf("cursor is invalid for this sort order")
+        }
+        params.cursor = decoded
+    }
+
+    return params, nil
+}
+
+// ParseOffsetParams reads limit and cursor for endpoints whose order cannot be
+// expressed as a keyset, such as search relevance. Their cursor is an opaque
+// offset into the result set.
+func ParseOffsetParams(c *gin.Context) (limit int, offset int, err error) {
+    limit = defaultPageLimit
+    if l := c.Query("limit"); l != "" {
+        n, err := strconv.Atoi(l)
+        if err != nil || n < 1 {
+            return 0, 0, fmt.Errorf("limit must be a positive integer")
+        }
+        limit = min(n, maxPageLimit)
+    }
+
+    if cursor := c.Query("cursor"); cursor != "" {
+        decoded, err := decodeCursor(cursor)
+        if err != nil || decoded.Sort != "offset" {
+            return 0, 0, fmt.Errorf("cursor is invalid")
+        }
+        offset = int(decoded.ID)
+    }
+
+    return limit, offset, nil
+}
+
+// OffsetPage builds the envelope for an offset-paginated endpoint.
+func OffsetPage(items interface{}, total int64, limit, offset int) Page {
+    page := Page{Items: items, Total: total}
+    if next := offset + limit; int64(next) < total {
+        data, _ := json.Marshal(pageCursor{Sort: "offset", ID: uint(next)})
+        cursor := base64.RawURLEncoding.EncodeToString(data)
+        page.NextCursor = &cursor
+    }
+    return page
+}
+
+func (p ListParams) sortName() string {
+    if p.Desc {
+        return "-" + p.SortKey
+    }
+    return p.SortKey
+}
+
+// Paginate counts the rows matched by query, then loads one page of them into
+// a slice of T ordered by the requested sort with the ID as tie-breaker.
+// Preloads are applied to the page query only. When fields are requested,
+// only their columns are read and only their relations are preloaded.
+func Paginate[T any](query *gorm.DB, params ListParams, preloads ...string) (Page, error) {
+    var page Page
+    if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+        return page, err
+    }
+
+    direction, comparison := "ASC", ">"
+    if params.Desc {
+        direction, comparison = "DESC", "<"
+    }
+
+    q := query.Session(&gorm.Session{})
+    if len(params.Fields) > 0 && len(q.Statement.Selects) == 0 {
+        var err error
+        if q, preloads, err = selectFields[T](q, params, preloads); err != nil {
+            return page, err
+        }
+    }
+    if params.cursor != nil {
+        value, err := params.cursorValue()
+        if err != nil {
+            return page, err
+        }
+        q = q.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", params.Sort.Column, comparison, params.Sort.Column, params.idField, comparison),
+            value, value, params.cursor.ID)
+    }
+    for _, preload := range preloads {
+        q = q.Preload(preload)
+    }
+
+    var items []T
+    if err := q.Order(params.Sort.Column + " " + direction).Order(params.idField + " " + direction).Limit(params.Limit + 1).Find(&items).Error; err != nil {
+        return page, err
+    }
+
+    if len(items) > params.Limit {
+        items = items[:params.Limit]
+        next, err := params.nextCursor(items[len(items)-1])
+        if err != nil {
+            return page, err
+        }
+        page.NextCursor = &next
+    }
+
+    if items == nil {
+        items = []T{}
+    }
+    page.Items = items
+    if len(params.Fields) > 0 {
+        selected, err := SelectFields(items, params.Fields)
+        if err != nil {
+            return page, err
+        }
+        page.Items = selected
+    }
+
+    return page, nil
+}
+
+func (p ListParams) cursorValue() (interface{}, error) {
+    switch p.Sort.Kind {
+    case SortTime:
+        s, _ := p.cursor.Value.(string)
+        return time.Parse(time.RFC3339Nano, s)
+    case SortNumber:
+        if n, ok := p.cursor.Value.(float64); ok {
+            return n, nil
+        }
+    default:
+        if s, ok := p.cursor.Value.(string); ok {
+            return s, nil
+        }
+    }
+    return nil, fmt.Errorf("cursor is invalid for this sort order")
+}
+
+func (p ListParams) nextCursor(item interface{}) (string, error) {
+    v := reflect.Indirect(reflect.ValueOf(item))
+    sortValue := v.FieldByName(p.Sort.Field)
+    idValue := v.FieldByName("ID")
+    if !sortValue.IsValid() || !idValue.IsValid() {
+        return "", fmt.Errorf("cannot build cursor from field %s", p.Sort.Field)
+    }
+
+    cursor := pageCursor{Sort: p.sortName(), Value: sortValue.Interface(), ID: uint(idValue.Uint())}
+    switch t := cursor.Value.(type) {
+    case time.Time:
+        cursor.Value = t.Format(time.RFC3339Nano)
+    case gorm.DeletedAt:
+        cursor.Value = t.Time.Format(time.RFC3339Nano)
+    }
+
+    data, err := json.Marshal(cursor)
+    if err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+    data, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return nil, err
+    }
+    var cursor pageCursor
+    if err := json.Unmarshal(data, &cursor); err != nil {
+        return nil, err
+    }
+    return &cursor, nil
+}
+
+// selectFields narrows the page query to the columns of T behind the
+// requested fields, plus the ID and sort columns pagination needs and the
+// keys of requested relations, and keeps only the preloads of those
+// relations.
+func selectFields[T any](q *gorm.DB, params ListParams, preloads []string) (*gorm.DB, []string, error) {
+    stmt := &gorm.Statement{DB: q}
+    if err := stmt.Parse(new(T)); err != nil {
+        return q, preloads, err
+    }
+    s := stmt.Schema
+
+    wanted := make(map[string]bool, len(params.Fields))
+    for _, f := range params.Fields {
+        wanted[strings.ToLower(f)] = true
+    }
+
+    var columns []string
+    seen := make(map[string]bool)
+    add := func(field *schema.Field) {
+        if field == nil || field.Schema != s || field.DBName == "" || seen[field.DBName] {
+            return
+        }
+        seen[field.DBName] = true
+        columns = append(columns, s.Table+"."+field.DBName)
+    }
+
+    add(s.PrioritizedPrimaryField)
+    add(s.LookUpField(params.Sort.Field))
+    for _, field := range s.Fields {
+        if wanted[jsonKey(field)] {
+            add(field)
+        }
+    }
+
+    var kept []string
+    for _, preload := range preloads {
+        relation, ok := s.Relationships.Relations[strings.SplitN(preload, ".", 2)[0]]
+        if !ok || !wanted[jsonKey(relation.Field)] {
+            continue
+        }
+        kept = append(kept, preload)
+        for _, ref := range relation.References {
+            add(ref.PrimaryKey)
+            add(ref.ForeignKey)
+        }
+    }
+
+    return q.Select(columns), kept, nil
+}
+
+// jsonKey returns the lowercased JSON key a field is marshalled under, or ""
+// for fields left out of JSON.
+func jsonKey(field *schema.Field) string {
+    name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+    switch name {
+    case "-":
+        return ""
+    case "":
+        return strings.ToLower(field.Name)
+    }
+    return strings.ToLower(name)
+}
+
+// SelectFields reduces each item to the requested top-level JSON keys. Keys
+// are matched case-insensitively so that "id,name" selects "ID" and "Name".
+func SelectFields(items interface{}, fields []string) ([]map[string]interface{}, error) {
+    data, err := json.Marshal(items)
+    if err != nil {
+        return nil, err
+    }
+    var decoded []map[string]interface{}
+    if err := json.Unmarshal(data, &decoded); err != nil {
+        return nil, err
+    }
+
+    wanted := make(map[string]bool, len(fields))
+    for _, f := range fields {
+        wanted[strings.ToLower(f)] = true
+    }
+
+    selected := make([]map[string]interface{}, 0, len(decoded))
+    for _, item := range decoded {
+        reduced := make(map[string]interface{})
+        for key, value := range item {
+            if wanted[strings.ToLower(key)] {
+                reduced[key] = value
+            }
+        }
+        selected = append(selected, reduced)
+    }
+    return selected, nil
+}
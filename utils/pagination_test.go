@@ -0,0 +1,192 @@
+package utils
+
+import (
+    "net/http/httptest"
+    "net/url"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/utils/tests"
+)
+
+type pageItem struct {
+    ID          uint
+    Name        string
+    Email       string `json:"email"`
+    Password    string `json:"-"`
+    Score       float64
+    CreatedDate time.Time
+    OwnerID     uint
+    Owner       pageOwner
+}
+
+type pageOwner struct {
+    ID   uint
+    Name string
+}
+
+var pageItemSort = map[string]SortField{
+    "created": {Column: "page_items.created_date", Field: "CreatedDate", Kind: SortTime},
+    "name":    {Column: "page_items.name", Field: "Name", Kind: SortString},
+    "score":   {Column: "page_items.score", Field: "Score", Kind: SortNumber},
+}
+
+func listContext(query url.Values) *gin.Context {
+    c, _ := gin.CreateTestContext(httptest.NewRecorder())
+    c.Request = httptest.NewRequest("GET", "/?"+query.Encode(), nil)
+    return c
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+    item := pageItem{
+        ID:          42,
+        Name:        "Ada",
+        Score:       87.5,
+        CreatedDate: time.Date(2024, time.March, 5, 14, 30, 15, 123456789, time.FixedZone("WIB", 7*60*60)),
+    }
+
+    tests := []struct {
+        sort string
+        want interface{}
+    }{
+        {sort: "created", want: item.CreatedDate},
+        {sort: "-created", want: item.CreatedDate},
+        {sort: "name", want: item.Name},
+        {sort: "-score", want: item.Score},
+    }
+    for _, test := range tests {
+        params, err := ParseListParams(listContext(url.Values{"sort": {test.sort}}), "page_items", pageItemSort)
+        if err != nil {
+            t.Fatalf("ParseListParams(sort=%s): %v", test.sort, err)
+        }
+        cursor, err := params.nextCursor(item)
+        if err != nil {
+            t.Fatalf("nextCursor(sort=%s): %v", test.sort, err)
+        }
+
+        params, err = ParseListParams(listContext(url.Values{"sort": {test.sort}, "cursor": {cursor}}), "page_items", pageItemSort)
+        if err != nil {
+            t.Errorf("ParseListParams(sort=%s) rejected its own cursor: %v", test.sort, err)
+            continue
+        }
+        if params.cursor.ID != item.ID {
+            t.Errorf("sort=%s: cursor ID = %d, want %d", test.sort, params.cursor.ID, item.ID)
+        }
+        got, err := params.cursorValue()
+        if err != nil {
+            t.Errorf("sort=%s: cursorValue: %v", test.sort, err)
+            continue
+        }
+        if want, ok := test.want.(time.Time); ok {
+            if got, ok := got.(time.Time); !ok || !got.Equal(want) {
+                t.Errorf("sort=%s: cursorValue = %v, want %v", test.sort, got, want)
+            }
+        } else if got != test.want {
+            t.Errorf("sort=%s: cursorValue = %v, want %v", test.sort, got, test.want)
+        }
+    }
+}
+
+func TestParseListParamsRejectsForeignCursor(t *testing.T) {
+    params, err := ParseListParams(listContext(url.Values{"sort": {"-created"}}), "page_items", pageItemSort)
+    if err != nil {
+        t.Fatalf("ParseListParams: %v", err)
+    }
+    cursor, err := params.nextCursor(pageItem{ID: 1, CreatedDate: time.Now()})
+    if err != nil {
+        t.Fatalf("nextCursor: %v", err)
+    }
+
+    tests := []struct {
+        sort   string
+        cursor string
+    }{
+        {sort: "created", cursor: cursor},
+        {sort: "name", cursor: cursor},
+        {sort: "", cursor: cursor},
+        {sort: "-created", cursor: "not a cursor"},
+        {sort: "-created", cursor: "eyJzIjoib2Zmc2V0IiwiaWQiOjUwfQ"},
+    }
+    for _, test := range tests {
+        query := url.Values{"cursor": {test.cursor}}
+        if test.sort != "" {
+            query.Set("sort", test.sort)
+        }
+        if _, err := ParseListParams(listContext(query), "page_items", pageItemSort); err == nil {
+            t.Errorf("ParseListParams(sort=%q, cursor=%q) accepted a cursor of another sort", test.sort, test.cursor)
+        }
+    }
+}
+
+func TestParseListParamsLimit(t *testing.T) {
+    tests := []struct {
+        limit string
+        want  int
+        err   bool
+    }{
+        {limit: "", want: defaultPageLimit},
+        {limit: "10", want: 10},
+        {limit: "1000", want: maxPageLimit},
+        {limit: "0", err: true},
+        {limit: "ten", err: true},
+    }
+    for _, test := range tests {
+        params, err := ParseListParams(listContext(url.Values{"limit": {test.limit}}), "page_items", pageItemSort)
+        if (err != nil) != test.err || (!test.err && params.Limit != test.want) {
+            t.Errorf("ParseListParams(limit=%q) = %d, %v", test.limit, params.Limit, err)
+        }
+    }
+}
+
+func TestSelectFields(t *testing.T) {
+    db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+    if err != nil {
+        t.Fatalf("opening database: %v", err)
+    }
+
+    tests := []struct {
+        fields   []string
+        preloads []string
+        columns  []string
+        kept     []string
+    }{
+        {
+            fields:  []string{"name", "EMAIL"},
+            columns: []string{"page_items.id", "page_items.created_date", "page_items.name", "page_items.email"},
+        },
+        {
+            // Fields left out of JSON cannot be selected.
+            fields:  []string{"password"},
+            columns: []string{"page_items.id", "page_items.created_date"},
+        },
+        {
+            // A requested relation keeps its preload and the key it joins on.
+            fields:   []string{"owner"},
+            preloads: []string{"Owner"},
+            columns:  []string{"page_items.id", "page_items.created_date", "page_items.owner_id"},
+            kept:     []string{"Owner"},
+        },
+        {
+            fields:   []string{"id"},
+            preloads: []string{"Owner"},
+            columns:  []string{"page_items.id", "page_items.created_date"},
+        },
+    }
+    for _, test := range tests {
+        params := ListParams{Sort: pageItemSort["created"], Fields: test.fields}
+        q, kept, err := selectFields[pageItem](db.Table("page_items"), params, test.preloads)
+        if err != nil {
+            t.Errorf("selectFields(%v): %v", test.fields, err)
+            continue
+        }
+        if columns := q.Statement.Selects; !reflect.DeepEqual(columns, test.columns) {
+            t.Errorf("selectFields(%v) columns = %v, want %v", test.fields, columns, test.columns)
+        }
+        if !reflect.DeepEqual(kept, test.kept) {
+            t.Errorf("selectFields(%v) preloads = %v, want %v", test.fields, kept, test.kept)
+        }
+    }
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+    "strings"
+    "unicode"
+)
+
+// NormalizeSkill returns the comparison key of a skill name: lowercased, with
+// surrounding punctuation trimmed and inner whitespace collapsed, so that
+// "Javascript " and "javascript" share a key while "C++" keeps its pluses.
+func NormalizeSkill(skill string) string {
+    skill = strings.Join(strings.Fields(strings.ToLower(skill)), " ")
+    return strings.TrimFunc(skill, func(r rune) bool {
+        return unicode.IsSpace(r) || strings.ContainsRune(".,;:-()[]\"'", r)
+    })
+}
+
+// SplitSkills splits a free-text skill list on commas, semicolons, pipes,
+// bullets and line breaks.
+func SplitSkills(skills string) []string {
+    parts := strings.FieldsFunc(skills, func(r rune) bool {
+        return r == ',' || r == ';' || r == '|' || r == '\n' || r == '•'
+    })
+
+    var result []string
+    for _, part := range parts {
+        if part = strings.TrimSpace(part); part != "" {
+            result = append(result, part)
+        }
+    }
+    return result
+}
+
+// PhraseKey lowercases s and rewrites it as space-separated words wrapped in
+// single spaces. A phrase is mentioned in a text as whole words when
+// PhraseKey(text) contains PhraseKey(phrase), so "Go" does not match inside
+// "Google" while "C++" and "Node.js" keep their punctuation.
+func PhraseKey(s string) string {
+    words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.", r)
+    })
+
+    var kept []string
+    for _, word := range words {
+        if word = strings.Trim(word, "."); word != "" {
+            kept = append(kept, word)
+        }
+    }
+    return " " + strings.Join(kept, " ") + " "
+}